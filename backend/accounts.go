@@ -22,6 +22,8 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/k0kubun/pp/v3"
 	"regexp"
+	"strings"
+	"time"
 )
 
 func paths(b *backend) []*framework.Path {
@@ -30,8 +32,54 @@ func paths(b *backend) []*framework.Path {
 		pathReadAndDelete(b),
 		pathSign(b),
 		pathSignAuth(b),
+		pathVerifyAuth(b),
+		pathAuthTemplateList(b),
+		pathAuthTemplate(b),
+		pathSIWISign(b),
+		pathSIWIVerify(b),
+		pathJWT(b),
+		pathJWKS(b),
+		pathSignCredential(b),
+		pathDIDDocument(b),
+		pathEncrypt(b),
+		pathDecrypt(b),
+		pathECDH(b),
+		pathEnableKeyAgreement(b),
+		pathDisableKeyAgreement(b),
+		pathCSR(b),
+		pathSignCertificate(b),
+		pathCertificateRoleList(b),
+		pathCertificateRole(b),
+		pathSignMerkle(b),
+		pathMerkleVerify(b),
 		pathParamSign(b),
+		pathSignMessage(b),
+		pathSignHash(b),
+		pathEnableSignHash(b),
+		pathDisableSignHash(b),
 		pathExport(b),
+		pathImportKeyStore(b),
+		pathWrappingKey(b),
+		pathConfig(b),
+		pathRotate(b),
+		pathRotateTransfer(b),
+		pathDisable(b),
+		pathEnable(b),
+		pathRestore(b),
+		pathRotationDue(b),
+		pathImportWrapped(b),
+		pathAuditList(b),
+		pathAuditRead(b),
+		pathHDWalletCreateAndList(b),
+		pathHDWalletReadAndDelete(b),
+		pathHDWalletDerive(b),
+		pathBackup(b),
+		pathHDWalletBackup(b),
+		pathRecoverAccount(b),
+		pathRecoverHDWallet(b),
+		pathBLSSign(b),
+		pathBLSProofOfPossession(b),
+		pathVerify(b),
 	}
 }
 
@@ -47,37 +95,37 @@ func ListMapResponse(keys []map[string]interface{}) *logical.Response {
 
 func (b *backend) listAccounts(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	detail := data.Get("detail").(bool)
-	vals, err := req.Storage.List(ctx, "accounts/")
+	filter := &accountFilter{
+		After:         data.Get("after").(string),
+		Limit:         data.Get("limit").(int),
+		AliasPrefix:   data.Get("alias_prefix").(string),
+		Labels:        data.Get("labels").(map[string]string),
+		Status:        data.Get("status").(string),
+		CreatedAfter:  int64(data.Get("created_after").(int)),
+		CreatedBefore: int64(data.Get("created_before").(int)),
+	}
+	vals, index, next, err := b.listAccountIndex(ctx, req, filter)
 	if err != nil {
 		b.Logger().Error("Failed to retrieve the list of accounts", "error", err)
 		return nil, err
 	}
+	var resp *logical.Response
 	if detail == true {
+		b.Logger().Info("Retrieve the list of accounts [detail]", "count", len(vals))
 		keyInfo := map[string]interface{}{}
-		b.Logger().Info("Retrieve the list of accounts [detail]", "error", err)
-		//var detailAddress []map[string]interface{}
 		for _, address := range vals {
-			account, _ := b.retrieveAccount(ctx, req, address)
-
-			if account.Address != "" {
-				//b.Logger().Info("[DETAIL] ", "address", account.Address, "alias_name", account.AliasName)
-				//_account := map[string]interface{}{
-				//	"address":    account.Address,
-				//	"alias_name": account.AliasName,
-				//}
-				//detailAddress = append(detailAddress, _account)
-				keyInfo[account.Address] = account.AliasName
-			}
-
+			keyInfo[address] = index[address].keyInfo()
 		}
-		//b.Logger().Info("[DETAIL] ", "ListMapResponse(detailAddress)", ListMapResponse(detailAddress))
-		//return ListMapResponse(detailAddress), nil
-		return logical.ListResponseWithInfo(vals, keyInfo), nil
+		resp = logical.ListResponseWithInfo(vals, keyInfo)
 	} else {
-		b.Logger().Info("[SIMPLE] Retrieve the list of accounts", "error", err, "detail", detail)
-		return logical.ListResponse(vals), nil
+		b.Logger().Info("[SIMPLE] Retrieve the list of accounts", "count", len(vals), "detail", detail)
+		resp = logical.ListResponse(vals)
 	}
-
+	// the next page starts after the last account read, which the filter may have dropped
+	if next != "" {
+		resp.Data["next"] = next
+	}
+	return resp, nil
 }
 
 func (b *backend) createAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -89,6 +137,15 @@ func (b *backend) createAccount(ctx context.Context, req *logical.Request, data
 
 	nameInput = data.Get("name").(string)
 	keyInput = data.Get("privateKey").(string)
+	exportable := data.Get("exportable").(bool)
+
+	switch keyType := data.Get("key_type").(string); keyType {
+	case KeyTypeSECP256K1:
+	case KeyTypeBLS12381:
+		return b.createBLSAccount(ctx, req, data)
+	default:
+		return nil, fmt.Errorf("unsupported key_type - %s", keyType)
+	}
 
 	if keyInput != "" {
 		re := regexp.MustCompile("[0-9a-fA-F]{64}$")
@@ -113,25 +170,21 @@ func (b *backend) createAccount(ctx context.Context, req *logical.Request, data
 		b.Logger().Info("Generate new private key", "address", publicKey.Address(), "publicKey", publicKey.String())
 	}
 
-	account, err := b.retrieveAccount(ctx, req, publicKey.Address())
-	if account != nil && account.Address == publicKey.Address() && account.AliasName == nameInput {
-		b.Logger().Info("Already key", "name", nameInput, "address", publicKey.Address(), "publicKey", publicKey.String())
-	} else {
-		accountPath := fmt.Sprintf("accounts/%s", publicKey.Address())
-		accountJSON := &Account{
-			Address:    publicKey.Address(),
-			PrivateKey: privateKey.String(),
-			PublicKey:  publicKey.String(),
-			AliasName:  nameInput,
-		}
-		entry, _ := logical.StorageEntryJSON(accountPath, accountJSON)
-		err = req.Storage.Put(ctx, entry)
-		if err != nil {
-			b.Logger().Error("[ERROR] Failed to save the new account to storage", "error", err)
-			return nil, err
-		} else {
-			b.Logger().Info("[OK] Save the new account", "Address", publicKey.Address())
-		}
+	stored, err := b.checkAccountConflict(ctx, req, publicKey.Address(), nameInput, data.Get("overwrite").(bool))
+	if err != nil {
+		b.Logger().Error("[ERROR] Failed to create the account", "name", nameInput, "address", publicKey.Address(), "error", err)
+		return nil, err
+	}
+	accountJSON := &Account{
+		Address:    publicKey.Address(),
+		PrivateKey: privateKey.String(),
+		PublicKey:  publicKey.String(),
+		AliasName:  nameInput,
+		Exportable: exportable,
+	}
+	accountJSON.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, accountJSON); err != nil {
+		return nil, err
 	}
 	return &logical.Response{
 		Data: map[string]interface{}{
@@ -153,12 +206,30 @@ func (b *backend) readAccount(ctx context.Context, req *logical.Request, data *f
 		//return nil, nil
 	}
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			"address":    account.Address,
-			"alias_name": account.AliasName,
-		},
-	}, nil
+	resp := &logical.Response{
+		Data: accountMetadata(account),
+	}
+	resp.Data["address"] = account.Address
+	resp.Data["alias_name"] = account.AliasName
+	resp.Data["status"] = account.Status
+	resp.Data["key_type"] = account.keyType()
+	if !account.IsSECP256K1() {
+		resp.Data["public_key"] = "0x" + account.PublicKey
+	}
+	if account.Status == AccountStatusPendingDeletion {
+		resp.Data["delete_after"] = account.DeleteAfter
+	}
+	if account.AllowSignHash {
+		resp.Data["allow_sign_hash"] = true
+	}
+	if account.AllowKeyAgreement {
+		resp.Data["allow_key_agreement"] = true
+	}
+	if account.DerivationPath != "" {
+		resp.Data["hd_wallet"] = account.HDWallet
+		resp.Data["derivation_path"] = account.DerivationPath
+	}
+	return resp, nil
 }
 
 func (b *backend) exportAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -172,15 +243,46 @@ func (b *backend) exportAccount(ctx context.Context, req *logical.Request, data
 		return nil, fmt.Errorf("[EXPORT][FAIL] Account does not exist - %s", address)
 	}
 
-	b.Logger().Info("[EXPORT][OK] ", "address", account.Address, ", alias_name", account.AliasName)
+	if !account.Exportable {
+		return nil, fmt.Errorf("[EXPORT][FAIL] Account is not exportable - %s", address)
+	}
+	// The exported key must never land in a plain response body.
+	if req.WrapInfo == nil || req.WrapInfo.TTL == 0 {
+		return nil, fmt.Errorf("[EXPORT][FAIL] Export requires response wrapping")
+	}
 
-	return &logical.Response{
+	resp := &logical.Response{
 		Data: map[string]interface{}{
 			"address":    account.Address,
 			"alias_name": account.AliasName,
-			//"privateKey": account.PrivateKey,
 		},
-	}, nil
+	}
+	passphrase := data.Get("passphrase").(string)
+	if passphrase != "" {
+		if !account.IsSECP256K1() {
+			return nil, fmt.Errorf("[EXPORT][FAIL] Keystore export is not supported for %s keys", account.KeyType)
+		}
+		privateKey, err := ParsePrivateKeyFromString(account.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		keyStore, err := EncryptKeyStore(privateKey, passphrase, data.Get("kdf").(string))
+		if err != nil {
+			return nil, err
+		}
+		resp.Data["keystore"] = keyStore
+	} else {
+		resp.Data["privateKey"] = account.PrivateKey
+	}
+
+	if err := b.recordAudit(ctx, req, "export", account.Address, map[string]interface{}{
+		"keystore": passphrase != "",
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[EXPORT][OK] ", "address", account.Address, ", alias_name", account.AliasName, "entity_id", req.EntityID)
+
+	return resp, nil
 }
 
 func (b *backend) deleteAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
@@ -196,23 +298,86 @@ func (b *backend) deleteAccount(ctx context.Context, req *logical.Request, data
 		return nil, fmt.Errorf("[DELETE][FAIL] %s", errorMsg)
 
 	}
-	if err := req.Storage.Delete(ctx, fmt.Sprintf("accounts/%s", account.Address)); err != nil {
-		b.Logger().Error("[DELETE][FAIL] Failed to delete the account from storage", "address", address, "error", err)
+	if account.Status == AccountStatusPendingDeletion {
+		return nil, fmt.Errorf("[DELETE][FAIL] Account is already pending deletion - %s", account.Address)
+	}
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
 		return nil, err
 	}
-	//b.Logger().Info("[DELETE][OK]", fmt.Sprintf("%v(%v) deleted successfully", "address", account.Address, account.AliasName))
-	b.Logger().Info("[DELETE][OK] Deleted successfully", "address", account.Address, "name", account.AliasName)
-	return nil, nil
+	if config.DeletionGracePeriod <= 0 {
+		if err := b.purgeAccount(ctx, req, account); err != nil {
+			return nil, err
+		}
+		if err := b.recordAudit(ctx, req, "delete", account.Address, nil); err != nil {
+			return nil, err
+		}
+		b.Logger().Info("[DELETE][OK] Deleted successfully", "address", account.Address, "name", account.AliasName)
+		return nil, nil
+	}
+
+	// the account is kept, with its alias, until the grace period ends
+	account.PreviousStatus = account.Status
+	account.Status = AccountStatusPendingDeletion
+	account.DeleteAfter = time.Now().Unix() + config.DeletionGracePeriod
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "delete", account.Address, map[string]interface{}{
+		"delete_after": account.DeleteAfter,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[DELETE][OK] Scheduled for deletion", "address", account.Address, "name", account.AliasName, "delete_after", account.DeleteAfter)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":      account.Address,
+			"status":       account.Status,
+			"delete_after": account.DeleteAfter,
+		},
+	}, nil
 }
 
+// purgeAccount permanently removes the account, its alias and its index entry.
+func (b *backend) purgeAccount(ctx context.Context, req *logical.Request, account *Account) error {
+	if err := req.Storage.Delete(ctx, fmt.Sprintf("accounts/%s", account.Address)); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the account from storage", "address", account.Address, "error", err)
+		return err
+	}
+	if err := b.deleteAlias(ctx, req, account.AliasName, account.Address); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the alias", "alias", account.AliasName, "error", err)
+		return err
+	}
+	if err := b.unindexAccount(ctx, req, account.Address); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to update the account index", "address", account.Address, "error", err)
+		return err
+	}
+	if err := req.Storage.Delete(ctx, "certificates/ca/"+account.Address); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the CA certificate", "address", account.Address, "error", err)
+		return err
+	}
+	return nil
+}
+
+// retrieveAccount returns the account by its address or alias.
 func (b *backend) retrieveAccount(ctx context.Context, req *logical.Request, address string) (*Account, error) {
 	var path string
 	matched, err := regexp.MatchString("^(hx)?[0-9a-fA-F]{40}$", address)
-	if !matched || err != nil {
-		b.Logger().Error("Failed to retrieve the account, malformatted account address", "address", address, "error", err)
-		return nil, fmt.Errorf("failed to retrieve the account, malformatted account address")
+	isBLSKeyID := blsKeyIDRegex.MatchString(address)
+	if (!matched || err != nil) && !isBLSKeyID {
+		resolved, err := b.lookupAlias(ctx, req, address)
+		if err != nil {
+			return nil, err
+		}
+		if resolved == "" {
+			b.Logger().Error("Failed to retrieve the account, unknown alias or malformatted account address", "address", address)
+			return nil, nil
+		}
+		return b.retrieveAccount(ctx, req, resolved)
 	} else {
-		if address[:2] != "hx" {
+		if isBLSKeyID {
+			address = strings.ToLower(address)
+		} else if address[:2] != "hx" {
 			address = "hx" + address
 		}
 		path = fmt.Sprintf("accounts/%s", address)
@@ -231,21 +396,71 @@ func (b *backend) retrieveAccount(ctx context.Context, req *logical.Request, add
 	}
 }
 
+func (b *backend) storeAccount(ctx context.Context, req *logical.Request, account *Account) error {
+	if account.Status == "" {
+		account.Status = AccountStatusActive
+	}
+	if account.CreatedAt == 0 {
+		account.CreatedAt = time.Now().Unix()
+	}
+	previous, err := b.retrieveAccount(ctx, req, account.Address)
+	if err != nil {
+		return err
+	}
+	// nothing is written unless the alias can be indexed
+	if err := validateAlias(account.AliasName); err != nil {
+		return err
+	}
+	owner, err := b.lookupAlias(ctx, req, account.AliasName)
+	if err != nil {
+		return err
+	}
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("accounts/%s", account.Address), account)
+	if err != nil {
+		return err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the new account to storage", "error", err)
+		return err
+	}
+	previousAlias := ""
+	if previous != nil {
+		previousAlias = previous.AliasName
+	}
+	if err := b.updateAliasIndex(ctx, req, account, previousAlias, owner); err != nil {
+		b.Logger().Error("[ERROR] Failed to update the alias index", "alias", account.AliasName, "error", err)
+		return err
+	}
+	if err := b.indexAccount(ctx, req, account); err != nil {
+		return err
+	}
+	b.Logger().Info("[OK] Save the new account", "Address", account.Address)
+	return nil
+}
+
 func (b *backend) signTx(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	b.Logger().Info("Start signTx")
 	var txHash []byte
 
 	serializeText := data.Get("serialize").(string)
 	params := data.Get("params").(map[string]interface{})
-	params["from"] = data.Get("name")
+	name := data.Get("name").(string)
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	from, err := b.resolveAddress(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if IsValidIconAddress(from) == false {
+		return nil, fmt.Errorf("Invalid 'address' value=%s, len=%d", name, len(name))
+	}
+	params["from"] = from
 	data.Raw["params"] = params
-	from := data.Get("name").(string)
 	delete(data.Raw, "name")
 	toAddr, _ := params["to"].(string)
 
-	if IsValidIconAddress(from) == false {
-		return nil, fmt.Errorf("Invalid 'address' value=%s, len=%d", from, len(from))
-	}
 	if IsValidIconAddress(toAddr) == false {
 		return nil, fmt.Errorf("Invalid 'to address' value=%s, len=%d", toAddr, len(toAddr))
 	}
@@ -298,6 +513,12 @@ func (b *backend) signTx(ctx context.Context, req *logical.Request, data *framew
 		return nil, fmt.Errorf("Error retrieving signing account %s", from)
 	}
 	b.Logger().Info("[LOAD] Loaded account", "address", account.Address)
+	if data.Get("dry_run").(bool) {
+		return &logical.Response{Data: dryRunResponse(account, []byte(serializeText), txHash)}, nil
+	}
+	if err := checkSigningPolicies(transactionSigningPolicies, account); err != nil {
+		return nil, err
+	}
 
 	if account == nil {
 		return nil, fmt.Errorf("Signing account %s does not exist", from)
@@ -332,6 +553,10 @@ func (b *backend) signTx(ctx context.Context, req *logical.Request, data *framew
 	params = data.Get("params").(map[string]interface{})
 	params["signature"] = b64Sig
 	data.Raw["params"] = params
+	signature, err := signedTx.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	b.Logger().Info("Payload", "payload", pp.Sprintf(ToJsonString(data.Raw)))
 	b.Logger().Info("transaction_hash", "transaction_hash", hex.EncodeToString(txHash))
@@ -341,7 +566,7 @@ func (b *backend) signTx(ctx context.Context, req *logical.Request, data *framew
 	return &logical.Response{
 		Data: map[string]interface{}{
 			"transaction_hash": "0x" + hex.EncodeToString(txHash),
-			"signature":        b64Sig,
+			"signature":        signature,
 			"serializeText":    serializeText,
 		},
 	}, nil
@@ -350,13 +575,22 @@ func (b *backend) signTx(ctx context.Context, req *logical.Request, data *framew
 func (b *backend) signAuth(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 
 	b.Logger().Info(">>>> Start signAuth")
-	walletAddress := data.Get("walletAddress").(string)
+	name := data.Get("walletAddress").(string)
 	time := data.Get("time")
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	walletAddress, err := b.resolveAddress(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
 	if IsValidIconAddress(walletAddress) == false {
-		return nil, fmt.Errorf("invalid 'walletAddress' value=%s, len=%d", walletAddress, len(walletAddress))
+		return nil, fmt.Errorf("invalid 'walletAddress' value=%s, len=%d", name, len(name))
 	}
-	if time == 0 {
-		return nil, fmt.Errorf("invalid 'time' value=%d", time)
+	requestSignText, err := b.authChallenge(ctx, req, data, walletAddress)
+	if err != nil {
+		return nil, err
 	}
 	b.Logger().Info("Params", "walletAddress", walletAddress, "time", time)
 
@@ -368,14 +602,23 @@ func (b *backend) signAuth(ctx context.Context, req *logical.Request, data *fram
 	if account == nil {
 		return nil, fmt.Errorf("signing account %s does not exist", walletAddress)
 	}
+	if !account.IsActive() {
+		return nil, fmt.Errorf("signing account %s is %s", walletAddress, account.Status)
+	}
+	if !account.IsSECP256K1() {
+		return nil, fmt.Errorf("signing account %s is a %s key", walletAddress, account.KeyType)
+	}
 
-	requestSignText := fmt.Sprintf("%s%d", walletAddress, time)
 	b.Logger().Info("Params", "requestSignText", requestSignText)
-	signature, err := SignFromPrivateKey(account.PrivateKey, []byte(requestSignText))
+	signedAuth, err := SignatureFromPrivateKey(account.PrivateKey, []byte(requestSignText))
 
 	if err != nil {
 		return nil, fmt.Errorf("signing error, address=%s, err=%v", walletAddress, err)
 	}
+	signature, err := signedAuth.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	return &logical.Response{
 		Data: map[string]interface{}{
@@ -394,11 +637,23 @@ func (b *backend) signTransaction(ctx context.Context, req *logical.Request, dat
 	var serializeByte []byte
 
 	serializeText := data.Get("serialize").(string)
-	from := data.Get("from").(string)
-
+	name := data.Get("from").(string)
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	dryRun := data.Get("dry_run").(bool)
+	// the output encoding and the dry run flag are not part of the transaction
+	delete(data.Raw, "signature_format")
+	delete(data.Raw, "dry_run")
+	from, err := b.resolveAddress(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
 	if IsValidIconAddress(from) == false {
-		return nil, fmt.Errorf("Invalid 'address' value=%s, len=%d", from, len(from))
+		return nil, fmt.Errorf("Invalid 'address' value=%s, len=%d", name, len(name))
 	}
+	data.Raw["from"] = from
 	b.Logger().Info("data.Raw", fmt.Sprintf("%v", data.Raw))
 
 	if serializeText != "" {
@@ -412,6 +667,7 @@ func (b *backend) signTransaction(ctx context.Context, req *logical.Request, dat
 		}
 		serializeByte = append(transactionSaltBytes, res...)
 	}
+	txHash = SHA3Sum256(serializeByte)
 
 	account, err := b.retrieveAccount(ctx, req, from)
 	if err != nil {
@@ -456,11 +712,23 @@ func (b *backend) signTransaction(ctx context.Context, req *logical.Request, dat
 		return nil, fmt.Errorf("Error reconstructing private key from retrieved hex")
 	}
 
-	b64Signature, err := SignFromPrivateKey(account.PrivateKey, serializeByte)
+	if dryRun {
+		return &logical.Response{Data: dryRunResponse(account, serializeByte, txHash)}, nil
+	}
+	if err := checkSigningPolicies(transactionSigningPolicies, account); err != nil {
+		return nil, err
+	}
+
+	signedTx, err := SignatureFromPrivateKey(account.PrivateKey, serializeByte)
 
 	if err != nil {
 		return nil, fmt.Errorf("signing error, address=%s, err=%v", account.PrivateKey, err)
 	}
+	b64Signature, _ := signedTx.EncodeBase64()
+	signature, err := signedTx.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	b.Logger().Info("Account Address", "address", account.Address)
 	b.Logger().Info("Signed Transaction based encoded 64", "signedTx_b64", b64Signature)
@@ -476,7 +744,7 @@ func (b *backend) signTransaction(ctx context.Context, req *logical.Request, dat
 	return &logical.Response{
 		Data: map[string]interface{}{
 			"txHash":        "0x" + hex.EncodeToString(txHash),
-			"signature":     b64Signature,
+			"signature":     signature,
 			"serialize":     BytesToString(serializeByte),
 			"account":       account.Address,
 			"signed_params": data.Raw,
@@ -108,8 +108,17 @@ func TestAccounts(t *testing.T) {
 
 	address1Expected := &logical.Response{
 		Data: map[string]interface{}{
-			"address":    address1,
-			"alias_name": address1AliasName,
+			"address":     address1,
+			"alias_name":  address1AliasName,
+			"status":      AccountStatusActive,
+			"key_type":    KeyTypeSECP256K1,
+			"description": "",
+			"owner_team":  "",
+			"purpose":     "",
+			"cost_center": "",
+			"labels":      map[string]string{},
+			"updated_at":  int64(0),
+			"updated_by":  "",
 		},
 	}
 	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address1)
@@ -153,6 +162,13 @@ func TestAccounts(t *testing.T) {
 		t.Fatal("error:", err)
 	}
 	pp.Print("signatureBytes", signatureBytes)
+	// delete immediately, without the grace period
+	req = logical.TestRequest(t, logical.UpdateOperation, "config")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"deletion_grace_period": 0}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 	// delete key by name
 	pp.Print("Delete key by address: ", address1, "\n\n")
 	req = logical.TestRequest(t, logical.DeleteOperation, "accounts/"+address1)
@@ -303,6 +319,8 @@ func TestListAccountsFailure1(t *testing.T) {
 	b, _ := getBackend(t)
 	req := logical.TestRequest(t, logical.ListOperation, "accounts")
 	sm := newStorageMock()
+	// no index yet, so it is rebuilt from the account list
+	sm.switches[1] = 1
 	req.Storage = sm
 	_, err := b.HandleRequest(context.Background(), req)
 
@@ -315,6 +333,7 @@ func TestCreateAccountsFailure1(t *testing.T) {
 	b, _ := getBackend(t)
 	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
 	sm := newStorageMock()
+	sm.switches[1] = 1
 	req.Storage = sm
 	_, err := b.HandleRequest(context.Background(), req)
 
@@ -371,20 +390,83 @@ func TestExportAccount(t *testing.T) {
 	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
 	aliasName := "ExportAccount"
 	data := map[string]interface{}{
-		"name": aliasName,
+		"name":       aliasName,
+		"exportable": true,
 	}
 	req.Data = data
 	storage := req.Storage
 	res, _ := b.HandleRequest(context.Background(), req)
 	address := res.Data["address"].(string)
-	req = logical.TestRequest(t, logical.ReadOperation, "export/accounts/"+address)
+	req = logical.TestRequest(t, logical.UpdateOperation, "export/accounts/"+address)
 	req.Storage = storage
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	req.Data = map[string]interface{}{
+		"passphrase": "ExportPassphrase",
+	}
 	resp, err := b.HandleRequest(context.Background(), req)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	assert.Equal(t, resp.Data["address"], address)
 	assert.Equal(t, resp.Data["alias_name"], aliasName)
+	assert.Equal(t, resp.Data["keystore"].(*KeyStore).Address, address)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "export/accounts/"+address)
+	req.Storage = storage
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	req.EntityID = "test-entity"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	privateKey, _ := ParsePrivateKeyFromString(resp.Data["privateKey"].(string))
+	assert.Equal(t, privateKey.PublicKey().Address(), address)
+
+	req = logical.TestRequest(t, logical.ListOperation, "audit")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	auditIDs := resp.Data["keys"].([]string)
+	assert.Equal(t, len(auditIDs), 2)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "audit/"+auditIDs[1])
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, resp.Data["event"], "export")
+	assert.Equal(t, resp.Data["address"], address)
+	assert.Equal(t, resp.Data["entity_id"], "test-entity")
+}
+
+func TestExportAccountFailure(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, _ := b.HandleRequest(context.Background(), req)
+	address := res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "export/accounts/"+address)
+	req.Storage = storage
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal(t, fmt.Sprintf("[EXPORT][FAIL] Account is not exportable - %s", address), err.Error())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"exportable": true,
+	}
+	res, _ = b.HandleRequest(context.Background(), req)
+	address = res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "export/accounts/"+address)
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(t, "[EXPORT][FAIL] Export requires response wrapping", err.Error())
 }
 
 func TestSignTransaction(t *testing.T) {
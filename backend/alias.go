@@ -0,0 +1,173 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+var (
+	accountAddressRegex = regexp.MustCompile("^(hx)?[0-9a-fA-F]{40}$")
+	// blsKeyIDRegex matches the ID of a BLS account, see BLSKeyID
+	blsKeyIDRegex = regexp.MustCompile("^0x[0-9a-fA-F]{96}$")
+)
+
+// aliasEntry maps a unique alias to the address of its account
+type aliasEntry struct {
+	Address string `json:"address"`
+}
+
+func aliasPath(alias string) string {
+	return fmt.Sprintf("aliases/%s", alias)
+}
+
+// resolveAddress returns the address for an account address or alias.
+// It returns an empty string if the alias is not registered.
+func (b *backend) resolveAddress(ctx context.Context, req *logical.Request, name string) (string, error) {
+	if accountAddressRegex.MatchString(name) {
+		if name[:2] != "hx" {
+			name = "hx" + name
+		}
+		return name, nil
+	}
+	if blsKeyIDRegex.MatchString(name) {
+		return strings.ToLower(name), nil
+	}
+	return b.lookupAlias(ctx, req, name)
+}
+
+func (b *backend) lookupAlias(ctx context.Context, req *logical.Request, alias string) (string, error) {
+	if alias == "" {
+		return "", nil
+	}
+	entry, err := req.Storage.Get(ctx, aliasPath(alias))
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the alias", "alias", alias, "error", err)
+		return "", err
+	}
+	if entry == nil {
+		return "", nil
+	}
+	var aliasEntry aliasEntry
+	if err := entry.DecodeJSON(&aliasEntry); err != nil {
+		return "", err
+	}
+	return aliasEntry.Address, nil
+}
+
+// checkAccountConflict rejects a new account whose address or alias is
+// already in use, unless overwrite is set. With overwrite, it returns the
+// stored account of the address, if any, whose state the caller must keep.
+func (b *backend) checkAccountConflict(ctx context.Context, req *logical.Request, address, alias string, overwrite bool) (*Account, error) {
+	account, err := b.retrieveAccount(ctx, req, address)
+	if err != nil {
+		return nil, err
+	}
+	if overwrite {
+		return account, nil
+	}
+	if account != nil {
+		return nil, fmt.Errorf("account already exists - %s", address)
+	}
+	owner, err := b.lookupAlias(ctx, req, alias)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" && owner != address {
+		return nil, fmt.Errorf("alias is already in use - %s", alias)
+	}
+	return nil, nil
+}
+
+// keepStoredState carries the state of a stored account over to the account
+// that overwrites it. The same address means the same key, so an overwrite
+// only reassigns the alias: it can't make a key exportable, re-enable a
+// retired, disabled or deleted account, or drop its permissions and metadata.
+func (a *Account) keepStoredState(stored *Account) {
+	if stored == nil {
+		return
+	}
+	aliasName := a.AliasName
+	*a = *stored
+	a.AliasName = aliasName
+}
+
+// validateAlias rejects an alias that could be taken for an address.
+func validateAlias(alias string) error {
+	if alias != "" && (accountAddressRegex.MatchString(alias) || blsKeyIDRegex.MatchString(alias)) {
+		return fmt.Errorf("alias must not be formatted as an address - %s", alias)
+	}
+	return nil
+}
+
+// updateAliasIndex points the alias of the account to its address. A
+// previous alias of the account is released, and owner, the account that
+// held the alias before, loses it. The alias must have been validated and
+// its owner looked up before the account was stored.
+func (b *backend) updateAliasIndex(ctx context.Context, req *logical.Request, account *Account, previousAlias, owner string) error {
+	if previousAlias != "" && previousAlias != account.AliasName {
+		if err := b.deleteAlias(ctx, req, previousAlias, account.Address); err != nil {
+			return err
+		}
+	}
+	if account.AliasName == "" {
+		return nil
+	}
+	if owner == account.Address {
+		return nil
+	}
+	if owner != "" {
+		previousOwner, err := b.retrieveAccount(ctx, req, owner)
+		if err != nil {
+			return err
+		}
+		if previousOwner != nil {
+			previousOwner.AliasName = ""
+			entry, err := logical.StorageEntryJSON(fmt.Sprintf("accounts/%s", previousOwner.Address), previousOwner)
+			if err != nil {
+				return err
+			}
+			if err := req.Storage.Put(ctx, entry); err != nil {
+				return err
+			}
+			if err := b.indexAccount(ctx, req, previousOwner); err != nil {
+				return err
+			}
+			b.Logger().Info("Alias moved to another account", "alias", account.AliasName, "from", owner, "to", account.Address)
+		}
+	}
+	entry, err := logical.StorageEntryJSON(aliasPath(account.AliasName), &aliasEntry{Address: account.Address})
+	if err != nil {
+		return err
+	}
+	return req.Storage.Put(ctx, entry)
+}
+
+// deleteAlias removes the alias if it still belongs to the address.
+func (b *backend) deleteAlias(ctx context.Context, req *logical.Request, alias, address string) error {
+	owner, err := b.lookupAlias(ctx, req, alias)
+	if err != nil {
+		return err
+	}
+	if owner != address {
+		return nil
+	}
+	return req.Storage.Delete(ctx, aliasPath(alias))
+}
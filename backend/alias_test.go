@@ -0,0 +1,166 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasLookup(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":       "treasury",
+		"privateKey": "d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32",
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/treasury")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, resp.Data["address"])
+
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/treasury/sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"params": map[string]interface{}{
+			"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+			"stepLimit": "0x4a817c800",
+			"value":     "0x2386f26fc10000",
+			"timestamp": "0x185cf742ec0",
+		},
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Contains(resp.Data["serializeText"], "from."+address)
+
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/treasury/param_sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+		"stepLimit": "0x4a817c800",
+		"value":     "0x2386f26fc10000",
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, resp.Data["account"])
+	assert.Contains(resp.Data["serialize"], "from."+address)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/unknown")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("[READ][FAIL] Account does not exist - unknown", err.Error())
+}
+
+func TestAliasUniqueness(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":       "treasury",
+		"privateKey": "d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32",
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the same key under another name
+	req.Data["name"] = "other"
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal("account already exists - hx10b1c9aee600db8eea807c77b5040ab294cdcf99", err.Error())
+
+	// another key under the same name
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name": "treasury",
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("alias is already in use - treasury", err.Error())
+
+	// overwrite moves the alias to the new account
+	req.Data["overwrite"] = true
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/treasury")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, resp.Data["address"])
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/hx10b1c9aee600db8eea807c77b5040ab294cdcf99")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("", resp.Data["alias_name"])
+
+	// deleting the account without a grace period releases the alias
+	req = logical.TestRequest(t, logical.UpdateOperation, "config")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"deletion_grace_period": 0}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.DeleteOperation, "accounts/treasury")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/treasury")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[READ][FAIL] Account does not exist - %s", "treasury"), err.Error())
+}
+
+func TestAliasFormattedAsAddress(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":       "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+		"privateKey": "d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32",
+	}
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal("alias must not be formatted as an address - hxc1d72af5b89ea6594a7e17ca7a804d52d2474462", err.Error())
+
+	// nothing was stored
+	account, err := b.(*backend).retrieveAccount(context.Background(), req, "hx10b1c9aee600db8eea807c77b5040ab294cdcf99")
+	assert.Nil(err)
+	assert.Nil(account)
+	req = logical.TestRequest(t, logical.ListOperation, "accounts")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(resp.Data["keys"])
+}
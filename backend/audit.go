@@ -0,0 +1,92 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// AuditEntry records a sensitive operation performed by the plugin
+type AuditEntry struct {
+	ID       string                 `json:"id"`
+	Time     int64                  `json:"time"`
+	Event    string                 `json:"event"`
+	Address  string                 `json:"address"`
+	EntityID string                 `json:"entity_id"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// recordAudit stores an audit entry for the event. Entry IDs are zero-padded
+// nanosecond timestamps so that listing returns them in chronological order.
+func (b *backend) recordAudit(ctx context.Context, req *logical.Request, event, address string, details map[string]interface{}) error {
+	now := time.Now()
+	entry := &AuditEntry{
+		ID:       fmt.Sprintf("%020d", now.UnixNano()),
+		Time:     now.Unix(),
+		Event:    event,
+		Address:  address,
+		EntityID: req.EntityID,
+		Details:  details,
+	}
+	storageEntry, err := logical.StorageEntryJSON("audit/"+entry.ID, entry)
+	if err != nil {
+		return err
+	}
+	if err := req.Storage.Put(ctx, storageEntry); err != nil {
+		b.Logger().Error("[AUDIT][FAIL] Failed to record the audit entry", "event", event, "address", address, "error", err)
+		return err
+	}
+	b.Logger().Info("[AUDIT]", "event", event, "address", address, "entity_id", req.EntityID)
+	return nil
+}
+
+func (b *backend) listAuditEntries(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	vals, err := req.Storage.List(ctx, "audit/")
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of audit entries", "error", err)
+		return nil, err
+	}
+	return logical.ListResponse(vals), nil
+}
+
+func (b *backend) readAuditEntry(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	id := data.Get("id").(string)
+	entry, err := req.Storage.Get(ctx, "audit/"+id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("[READ][FAIL] Audit entry does not exist - %s", id)
+	}
+	var auditEntry AuditEntry
+	if err := entry.DecodeJSON(&auditEntry); err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"id":        auditEntry.ID,
+			"time":      auditEntry.Time,
+			"event":     auditEntry.Event,
+			"address":   auditEntry.Address,
+			"entity_id": auditEntry.EntityID,
+			"details":   auditEntry.Details,
+		},
+	}, nil
+}
@@ -0,0 +1,269 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// DefaultAuthTemplate is the challenge signed by sign_auth without a
+	// template, the wallet address followed by the time, as the Planet manager expects
+	DefaultAuthTemplate = "{address}{time}"
+	// DefaultAuthTimeWindow is the maximum distance in seconds between the time of an auth challenge and now
+	DefaultAuthTimeWindow = 5 * 60
+)
+
+var (
+	authPlaceholderRegex = regexp.MustCompile(`\{([a-z]+)\}`)
+	// authPlaceholders are the values a template can use; address and time are required
+	authPlaceholders = map[string]bool{"address": true, "time": true, "nonce": true, "domain": true}
+	// the nonce and the domain are chosen by the caller, so they are kept to
+	// characters that cannot change the meaning of the challenge
+	authNonceRegex  = regexp.MustCompile(`^[A-Za-z0-9]{8,64}$`)
+	authDomainRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]{0,251}[A-Za-z0-9])?(:[0-9]{1,5})?$`)
+)
+
+// AuthTemplate is a named format of the auth challenge
+type AuthTemplate struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+}
+
+// validateAuthFormat checks that the format uses known placeholders and
+// binds the challenge to an address and a time.
+func validateAuthFormat(format string) error {
+	used := map[string]bool{}
+	for _, match := range authPlaceholderRegex.FindAllStringSubmatch(format, -1) {
+		if !authPlaceholders[match[1]] {
+			return fmt.Errorf("unknown placeholder {%s}", match[1])
+		}
+		used[match[1]] = true
+	}
+	if !used["address"] || !used["time"] {
+		return fmt.Errorf("format must contain {address} and {time}")
+	}
+	return nil
+}
+
+// validateAuthValues checks the nonce and the domain of a challenge, which
+// are optional.
+func validateAuthValues(nonce, domain string) error {
+	if nonce != "" && !authNonceRegex.MatchString(nonce) {
+		return fmt.Errorf("nonce must be 8 to 64 alphanumeric characters")
+	}
+	if domain != "" && !authDomainRegex.MatchString(domain) {
+		return fmt.Errorf("domain must be a host name with an optional port - %s", domain)
+	}
+	return nil
+}
+
+// renderAuthChallenge replaces the placeholders of the format with the values.
+func renderAuthChallenge(format string, values map[string]string) (string, error) {
+	var missing string
+	challenge := authPlaceholderRegex.ReplaceAllStringFunc(format, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if values[name] == "" && missing == "" {
+			missing = name
+		}
+		return values[name]
+	})
+	if missing != "" {
+		return "", fmt.Errorf("auth challenge requires %s", missing)
+	}
+	return challenge, nil
+}
+
+// checkAuthTime returns an error if the time of the challenge is not within
+// the freshness window.
+func checkAuthTime(authTime, window int64) error {
+	if authTime <= 0 {
+		return fmt.Errorf("invalid 'time' value=%d", authTime)
+	}
+	age := time.Now().Unix() - authTime
+	if age > window || age < -window {
+		return fmt.Errorf("time %d is outside the freshness window of %d seconds", authTime, window)
+	}
+	return nil
+}
+
+func (b *backend) retrieveAuthTemplate(ctx context.Context, req *logical.Request, name string) (*AuthTemplate, error) {
+	entry, err := req.Storage.Get(ctx, "auth_templates/"+name)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the auth template", "name", name, "error", err)
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	var template AuthTemplate
+	if err := entry.DecodeJSON(&template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// authChallenge returns the challenge for the address from the request
+// fields, after checking the freshness of its time.
+func (b *backend) authChallenge(ctx context.Context, req *logical.Request, data *framework.FieldData, address string) (string, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	authTime := int64(data.Get("time").(int))
+	if err := checkAuthTime(authTime, config.AuthTimeWindow); err != nil {
+		return "", err
+	}
+
+	format := DefaultAuthTemplate
+	if name := data.Get("template").(string); name != "" {
+		template, err := b.retrieveAuthTemplate(ctx, req, name)
+		if err != nil {
+			return "", err
+		}
+		if template == nil {
+			return "", fmt.Errorf("auth template does not exist - %s", name)
+		}
+		format = template.Format
+	}
+	nonce, domain := data.Get("nonce").(string), data.Get("domain").(string)
+	if err := validateAuthValues(nonce, domain); err != nil {
+		return "", err
+	}
+	challenge, err := renderAuthChallenge(format, map[string]string{
+		"address": address,
+		"time":    strconv.FormatInt(authTime, 10),
+		"nonce":   nonce,
+		"domain":  domain,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := checkNotTransaction("auth challenge", challenge); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// checkNotTransaction fails if a text that is hashed without a prefix reads
+// as a transaction, whose hash is the salted serialization.
+func checkNotTransaction(name, text string) error {
+	if strings.HasPrefix(text, string(transactionSaltBytes)) {
+		return fmt.Errorf("%s must not start with %s", name, transactionSaltBytes)
+	}
+	return nil
+}
+
+func (b *backend) verifyAuth(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("walletAddress").(string)
+	walletAddress, err := b.resolveAddress(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if IsValidIconAddress(walletAddress) == false {
+		return nil, fmt.Errorf("invalid 'walletAddress' value=%s, len=%d", name, len(name))
+	}
+	signature, err := ParseSignatureString(data.Get("signature").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"walletAddr": walletAddress,
+			"valid":      false,
+		},
+	}
+	challenge, err := b.authChallenge(ctx, req, data, walletAddress)
+	if err != nil {
+		resp.Data["reason"] = err.Error()
+		return resp, nil
+	}
+	resp.Data["challenge"] = challenge
+
+	hash := SHA3Sum256([]byte(challenge))
+	publicKey, err := signature.RecoverPublicKey(hash)
+	switch {
+	case err != nil:
+		resp.Data["reason"] = err.Error()
+	case !signature.Verify(hash, publicKey) || publicKey.Address() != walletAddress:
+		resp.Data["reason"] = "signature is not made by " + walletAddress
+	default:
+		resp.Data["valid"] = true
+	}
+	return resp, nil
+}
+
+func (b *backend) listAuthTemplates(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	vals, err := req.Storage.List(ctx, "auth_templates/")
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of auth templates", "error", err)
+		return nil, err
+	}
+	return logical.ListResponse(vals), nil
+}
+
+func (b *backend) readAuthTemplate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	template, err := b.retrieveAuthTemplate(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, fmt.Errorf("[READ][FAIL] Auth template does not exist - %s", name)
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":   template.Name,
+			"format": template.Format,
+		},
+	}, nil
+}
+
+func (b *backend) writeAuthTemplate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	template := &AuthTemplate{
+		Name:   data.Get("name").(string),
+		Format: data.Get("format").(string),
+	}
+	if err := validateAuthFormat(template.Format); err != nil {
+		return nil, err
+	}
+	entry, err := logical.StorageEntryJSON("auth_templates/"+template.Name, template)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the auth template", "name", template.Name, "error", err)
+		return nil, err
+	}
+	return b.readAuthTemplate(ctx, req, data)
+}
+
+func (b *backend) deleteAuthTemplate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if err := req.Storage.Delete(ctx, "auth_templates/"+name); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the auth template", "name", name, "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
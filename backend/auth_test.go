@@ -0,0 +1,169 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAuthChallenge(t *testing.T) {
+	values := map[string]string{"address": "hx1", "time": "2", "domain": "planet.io"}
+	challenge, err := renderAuthChallenge(DefaultAuthTemplate, values)
+	assert.Nil(t, err)
+	assert.Equal(t, "hx12", challenge)
+
+	challenge, err = renderAuthChallenge("{domain}:{address}:{time}", values)
+	assert.Nil(t, err)
+	assert.Equal(t, "planet.io:hx1:2", challenge)
+
+	_, err = renderAuthChallenge("{address}{time}{nonce}", values)
+	assert.EqualError(t, err, "auth challenge requires nonce")
+
+	assert.EqualError(t, validateAuthFormat("{address}{when}"), "unknown placeholder {when}")
+	assert.EqualError(t, validateAuthFormat("{address} {nonce}"), "format must contain {address} and {time}")
+	assert.Nil(t, validateAuthFormat("{domain} {address} {time} {nonce}"))
+
+	assert.Nil(t, validateAuthValues("", ""))
+	assert.Nil(t, validateAuthValues("a1b2c3d4", "planet.io:8443"))
+	assert.EqualError(t, validateAuthValues("n-1", ""), "nonce must be 8 to 64 alphanumeric characters")
+	assert.EqualError(t, validateAuthValues(strings.Repeat("a", 65), ""), "nonce must be 8 to 64 alphanumeric characters")
+	assert.EqualError(t, validateAuthValues("", "planet.io\nhx1"), "domain must be a host name with an optional port - planet.io\nhx1")
+	assert.EqualError(t, validateAuthValues("", "icx_sendTransaction."), "domain must be a host name with an optional port - icx_sendTransaction.")
+	assert.NotNil(t, validateAuthValues("", strings.Repeat("a", 254)))
+}
+
+func TestSignAndVerifyAuth(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "auth_templates/login")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"format": "{domain} login {address} at {time} nonce {nonce}"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.ListOperation, "auth_templates")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, []string{"login"}, resp.Data["keys"])
+
+	now := time.Now().Unix()
+	challenge := map[string]interface{}{
+		"walletAddress": address,
+		"time":          now,
+		"template":      "login",
+		"nonce":         "login0001",
+		"domain":        "planet.io",
+	}
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/sign_auth")
+	req.Storage = storage
+	req.Data = challenge
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	signature := resp.Data["signature"].(string)
+
+	verifyAuth := func(data map[string]interface{}) *logical.Response {
+		req := logical.TestRequest(t, logical.UpdateOperation, "verify_auth")
+		req.Storage = storage
+		req.Data = data
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	challenge["signature"] = signature
+	resp = verifyAuth(challenge)
+	assert.Equal(t, true, resp.Data["valid"])
+	assert.Equal(t, fmt.Sprintf("planet.io login %s at %d nonce login0001", address, now), resp.Data["challenge"])
+
+	challenge["nonce"] = "login0002"
+	resp = verifyAuth(challenge)
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, "signature is not made by "+address, resp.Data["reason"])
+
+	challenge["nonce"] = "login0001"
+	challenge["time"] = now - DefaultAuthTimeWindow - 1
+	resp = verifyAuth(challenge)
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, fmt.Sprintf("time %d is outside the freshness window of %d seconds", now-DefaultAuthTimeWindow-1, DefaultAuthTimeWindow), resp.Data["reason"])
+
+	// a challenge cannot pass for a transaction
+	req = logical.TestRequest(t, logical.UpdateOperation, "auth_templates/tx")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"format": "icx_sendTransaction.from.{address}.timestamp.{time}"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/sign_auth")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"walletAddress": address, "time": now, "template": "tx"}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.EqualError(t, err, "auth challenge must not start with icx_sendTransaction.")
+}
+
+func TestSignAuthFreshness(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	signAuth := func(data map[string]interface{}) error {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/sign_auth")
+		req.Storage = storage
+		req.Data = data
+		_, err := b.HandleRequest(context.Background(), req)
+		return err
+	}
+	assert.EqualError(t, signAuth(map[string]interface{}{"time": 1660000000}),
+		fmt.Sprintf("time 1660000000 is outside the freshness window of %d seconds", DefaultAuthTimeWindow))
+	assert.EqualError(t, signAuth(map[string]interface{}{}), "invalid 'time' value=0")
+	assert.EqualError(t, signAuth(map[string]interface{}{"time": time.Now().Unix(), "template": "missing"}),
+		"auth template does not exist - missing")
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "config")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"auth_time_window": "87600h"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(t, signAuth(map[string]interface{}{"time": 1660000000}))
+
+	req.Data = map[string]interface{}{"auth_time_window": 0}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.EqualError(t, err, "auth_time_window must be positive")
+}
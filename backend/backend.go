@@ -17,8 +17,10 @@ package backend
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -37,6 +39,7 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 // Backend returns the backend
 func Backend() (*backend, error) {
 	var b backend
+	b.hdWalletLocks = locksutil.CreateLocks()
 	b.Backend = &framework.Backend{
 		Help: "",
 		Paths: framework.PathAppend(
@@ -45,10 +48,13 @@ func Backend() (*backend, error) {
 		PathsSpecial: &logical.Paths{
 			SealWrapStorage: []string{
 				"accounts/",
+				"hd/",
+				"config/",
 			},
 		},
-		Secrets:     []*framework.Secret{},
-		BackendType: logical.TypeLogical,
+		Secrets:      []*framework.Secret{},
+		BackendType:  logical.TypeLogical,
+		PeriodicFunc: b.purgeDeletedAccounts,
 	}
 	return &b, nil
 }
@@ -56,6 +62,15 @@ func Backend() (*backend, error) {
 // backend implements the Backend for this plugin
 type backend struct {
 	*framework.Backend
+
+	// wrappingKeyLock serializes the lazy generation of the wrapping key
+	wrappingKeyLock sync.Mutex
+	// indexLock serializes the build of the account index
+	indexLock sync.Mutex
+	// indexBuilt is set once the account index is known to be built
+	indexBuilt bool
+	// hdWalletLocks serialize the updates of an HD wallet, by name
+	hdWalletLocks []*locksutil.LockEntry
 }
 
 func (b *backend) pathExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
@@ -0,0 +1,278 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// splitToRecipients splits the secret into one share per recipient and
+// encrypts each hex encoded share to its recipient.
+func splitToRecipients(secret []byte, recipients []string, threshold int) ([]string, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("recipients are required")
+	}
+	shares, err := ShamirSplit(secret, len(recipients), threshold)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := make([]string, len(shares))
+	for i, share := range shares {
+		if encrypted[i], err = EncryptToRecipient(recipients[i], []byte(hex.EncodeToString(share))); err != nil {
+			return nil, fmt.Errorf("failed to encrypt share %d: %v", i+1, err)
+		}
+	}
+	return encrypted, nil
+}
+
+// combineShares rebuilds the secret from the decrypted hex encoded shares.
+func combineShares(encodedShares []string) ([]byte, error) {
+	shares := make([][]byte, len(encodedShares))
+	for i, encoded := range encodedShares {
+		share, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(encoded), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid share %d", i+1)
+		}
+		shares[i] = share
+	}
+	return ShamirCombine(shares)
+}
+
+func (b *backend) backupAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("name").(string)
+	recipients := data.Get("recipients").([]string)
+	threshold := data.Get("threshold").(int)
+
+	account, err := b.retrieveAccount(ctx, req, address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("[BACKUP][FAIL] Account does not exist - %s", address)
+	}
+	// backup shares leave the plugin, like an export
+	if !account.Exportable {
+		return nil, fmt.Errorf("[BACKUP][FAIL] Account is not exportable - %s", address)
+	}
+	if !account.IsSECP256K1() {
+		return nil, fmt.Errorf("[BACKUP][FAIL] Backup is not supported for %s keys", account.KeyType)
+	}
+	privateKey, err := ParsePrivateKeyFromString(account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := splitToRecipients(privateKey.Bytes(), recipients, threshold)
+	if err != nil {
+		b.Logger().Error("[BACKUP][FAIL] Failed to split the key", "address", account.Address, "error", err)
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "backup", account.Address, map[string]interface{}{
+		"shares":    len(shares),
+		"threshold": threshold,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[BACKUP][OK] Split the account key", "address", account.Address, "shares", len(shares), "threshold", threshold)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":   account.Address,
+			"threshold": threshold,
+			"shares":    shares,
+		},
+	}, nil
+}
+
+func (b *backend) backupHDWallet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	recipients := data.Get("recipients").([]string)
+	threshold := data.Get("threshold").(int)
+
+	lock := locksutil.LockForKey(b.hdWalletLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	wallet, err := b.retrieveHDWallet(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("HD wallet does not exist - %s", name)
+	}
+	// the seed gives away every account derived from it
+	if err := b.checkHDWalletExportable(ctx, req, name); err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(wallet.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the stored seed: %v", err)
+	}
+	// the first account of the wallet is used to check the recovered seed
+	privateKey, err := DerivePrivateKey(seed, ICONDerivationPath(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	shares, err := splitToRecipients(seed, recipients, threshold)
+	if err != nil {
+		b.Logger().Error("[BACKUP][FAIL] Failed to split the seed", "wallet", name, "error", err)
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "backup_hd", "", map[string]interface{}{
+		"wallet":    name,
+		"shares":    len(shares),
+		"threshold": threshold,
+	}); err != nil {
+		return nil, err
+	}
+	if !wallet.Exported {
+		wallet.Exported = true
+		if err := b.storeHDWallet(ctx, req, wallet); err != nil {
+			return nil, err
+		}
+	}
+	b.Logger().Info("[BACKUP][OK] Split the HD wallet seed", "wallet", name, "shares", len(shares), "threshold", threshold)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":      name,
+			"address":   privateKey.PublicKey().Address(),
+			"threshold": threshold,
+			"shares":    shares,
+		},
+	}, nil
+}
+
+// checkHDWalletExportable fails if an account derived from the wallet is not
+// exportable.
+func (b *backend) checkHDWalletExportable(ctx context.Context, req *logical.Request, name string) error {
+	addresses, index, err := b.listAllAccountIndex(ctx, req, &accountFilter{HDWallet: name})
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of accounts", "error", err)
+		return err
+	}
+	for _, address := range addresses {
+		if !index[address].Exportable {
+			return fmt.Errorf("[BACKUP][FAIL] HD wallet %s has a non-exportable account - %s", name, address)
+		}
+	}
+	return nil
+}
+
+func (b *backend) recoverAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("address").(string)
+	aliasName := data.Get("name").(string)
+
+	secret, err := combineShares(data.Get("shares").([]string))
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := ParsePrivateKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("[RECOVER][FAIL] Recovered key is invalid, wrong or missing shares")
+	}
+	publicKey := privateKey.PublicKey()
+	if publicKey.Address() != address {
+		return nil, fmt.Errorf("[RECOVER][FAIL] Recovered address %s does not match %s", publicKey.Address(), address)
+	}
+	stored, err := b.checkAccountConflict(ctx, req, address, aliasName, data.Get("overwrite").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Address:    address,
+		PrivateKey: privateKey.String(),
+		PublicKey:  publicKey.String(),
+		AliasName:  aliasName,
+		Exportable: data.Get("exportable").(bool),
+	}
+	account.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "recover", account.Address, nil); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[RECOVER][OK] Recovered account", "address", account.Address)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"alias_name": account.AliasName,
+		},
+	}, nil
+}
+
+func (b *backend) recoverHDWallet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	address := data.Get("address").(string)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	lock := locksutil.LockForKey(b.hdWalletLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	wallet, err := b.retrieveHDWallet(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if wallet != nil {
+		return nil, fmt.Errorf("HD wallet already exists - %s", name)
+	}
+
+	seed, err := combineShares(data.Get("shares").([]string))
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := DerivePrivateKey(seed, ICONDerivationPath(0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("[RECOVER][FAIL] Recovered seed is invalid, wrong or missing shares")
+	}
+	if derived := privateKey.PublicKey().Address(); derived != address {
+		return nil, fmt.Errorf("[RECOVER][FAIL] Recovered address %s does not match %s", derived, address)
+	}
+
+	wallet = &HDWallet{
+		Name:      name,
+		Seed:      hex.EncodeToString(seed),
+		Exported:  true,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := b.storeHDWallet(ctx, req, wallet); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "recover_hd", "", map[string]interface{}{
+		"wallet": name,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[RECOVER][OK] Recovered HD wallet", "wallet", name)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":    name,
+			"address": address,
+		},
+	}, nil
+}
@@ -0,0 +1,375 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func TestShamir(t *testing.T) {
+	assert := assert.New(t)
+	secret := make([]byte, 32)
+	rand.Read(secret)
+
+	shares, err := ShamirSplit(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var parts [][]byte
+		for _, i := range subset {
+			parts = append(parts, shares[i])
+		}
+		combined, err := ShamirCombine(parts)
+		assert.Nil(err)
+		assert.Equal(secret, combined, subset)
+	}
+	combined, err := ShamirCombine(shares[:2])
+	assert.Nil(err)
+	assert.NotEqual(secret, combined)
+
+	_, err = ShamirCombine([][]byte{shares[0], shares[0]})
+	assert.Equal("duplicate or invalid share", err.Error())
+	_, err = ShamirSplit(secret, 2, 3)
+	assert.Equal("shares (2) cannot be less than the threshold (3)", err.Error())
+}
+
+func TestBackupRecoverAccountPGP(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	entity, err := openpgp.NewEntity("custodian", "", "custodian@example.com", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	publicKey := &bytes.Buffer{}
+	w, _ := armor.Encode(publicKey, openpgp.PublicKeyType, nil)
+	entity.Serialize(w)
+	w.Close()
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	nonExportable := res.Data["address"].(string)
+	recipients := []string{publicKey.String(), publicKey.String(), publicKey.String()}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+nonExportable+"/backup")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"recipients": recipients,
+		"threshold":  2,
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[BACKUP][FAIL] Account is not exportable - %s", nonExportable), err.Error())
+
+	address := "hx10b1c9aee600db8eea807c77b5040ab294cdcf99"
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"privateKey": "d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32",
+		"exportable": true,
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/backup")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"recipients": recipients,
+		"threshold":  2,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	encrypted := resp.Data["shares"].([]string)
+	assert.Equal(3, len(encrypted))
+
+	var shares []string
+	for _, share := range encrypted {
+		assert.False(strings.Contains(share, "d25d2854"))
+		block, err := armor.Decode(strings.NewReader(share))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		md, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		plain, err := ioutil.ReadAll(md.UnverifiedBody)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		shares = append(shares, string(plain))
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "recover/account")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"shares":  []string{shares[0], shares[2]},
+		"address": "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[RECOVER][FAIL] Recovered address %s does not match hxc1d72af5b89ea6594a7e17ca7a804d52d2474462", address), err.Error())
+
+	req.Data["address"] = address
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("account already exists - "+address, err.Error())
+
+	req.Data["overwrite"] = true
+	req.Data["name"] = "recovered"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, resp.Data["address"])
+	assert.Nil(signWithAccount(t, b, storage, "recovered"))
+}
+
+func TestBackupRecoverHDWalletAge(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	identity := make([]byte, curve25519.ScalarSize)
+	rand.Read(identity)
+	recipient := testAgeRecipient(t, identity)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "hd")
+	req.Storage = storage
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	req.Data = map[string]interface{}{"name": "treasury"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/derive")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"exportable": true}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	firstAddress := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/backup")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"recipients": []string{recipient, recipient, recipient},
+		"threshold":  3,
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(firstAddress, resp.Data["address"])
+
+	// the seed exists outside the plugin, so no non-exportable accounts
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/derive")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.NotNil(err)
+
+	var shares []string
+	for _, share := range resp.Data["shares"].([]string) {
+		shares = append(shares, string(testAgeDecrypt(t, identity, share)))
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "recover/hd")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":    "restored",
+		"shares":  shares[:2],
+		"address": firstAddress,
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.NotNil(err)
+
+	req.Data["shares"] = shares
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/restored/derive")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"index": 0, "overwrite": true, "exportable": true}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(firstAddress, resp.Data["address"])
+}
+
+func TestBackupHDWalletNonExportable(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	identity := make([]byte, curve25519.ScalarSize)
+	rand.Read(identity)
+	recipient := testAgeRecipient(t, identity)
+
+	storeLegacyHDWallet(t, b, storage, "treasury")
+	req := logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/derive")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/backup")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"recipients": []string{recipient, recipient},
+		"threshold":  2,
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[BACKUP][FAIL] HD wallet treasury has a non-exportable account - %s", address), err.Error())
+
+	wallet, err := b.(*backend).retrieveHDWallet(context.Background(), &logical.Request{Storage: storage}, "treasury")
+	assert.Nil(err)
+	assert.False(wallet.Exported)
+}
+
+func TestParseAgeRecipient(t *testing.T) {
+	assert := assert.New(t)
+	key := make([]byte, 32)
+	rand.Read(key)
+	recipient := testBech32Encode("age", key)
+	parsed, err := parseAgeRecipient(recipient)
+	assert.Nil(err)
+	assert.Equal(key, parsed)
+
+	corrupted := recipient[:len(recipient)-1] + "q"
+	if corrupted == recipient {
+		corrupted = recipient[:len(recipient)-1] + "p"
+	}
+	_, err = parseAgeRecipient(corrupted)
+	assert.Equal("invalid age recipient checksum", err.Error())
+}
+
+// The age files were checked with age v1.2.1: it decrypts the first with the
+// identity AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX,
+// and produced the second with "age -a -r".
+func TestAgeVector(t *testing.T) {
+	assert := assert.New(t)
+	identity := bytes.Repeat([]byte{0x42}, 32)
+	recipient := testAgeRecipient(t, identity)
+	assert.Equal("age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj", recipient)
+
+	recipientKey, err := parseAgeRecipient(recipient)
+	assert.Nil(err)
+	sealed, err := sealAge(recipientKey, []byte("hello ICON"),
+		bytes.Repeat([]byte{0x01}, 16), bytes.Repeat([]byte{0x03}, 32), bytes.Repeat([]byte{0x05}, 16))
+	assert.Nil(err)
+	assert.Equal(`-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBYZjdkTzJ2VWYyK2lqdUZk
+bHAxYnNPcFRkMDFJaTlyNTN4eHVBU1N6N3lJCk92b0RnWW1TRUQ0SzVhNCtLRFhJ
+cHpTZkJSRDQvWnJ5UUFlbWF1WWFOWWMKLS0tIDZvWmJjUHVkY0VZVFg0bk9wVU9R
+UDhJcEFTYVh5cTZMcDg1dlJiZmdKYUkKBQUFBQUFBQUFBQUFBQUFBTywJS6k4Y7N
+rrHpPnPfWmQvlTj4o3r3xgrH
+-----END AGE ENCRYPTED FILE-----
+`, sealed)
+
+	produced := `-----BEGIN AGE ENCRYPTED FILE-----
+YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSBiR2pyM0RCdkRRcEdIbng2
+bXUyK2dlTUZ3bW10Y1hVeGZ2VUtsWmZsNlVNClVVYXR1Z2FBNHhqK0dyQTRVN2Uv
+bU5peXZHQVJremhrM3Q0S2htTXFKckkKLS0tIEZmeG5DNll0aVQyM2NZZTBlSVRk
+OG1pQldYVGtuMlp6eFBSaDFqLyticG8K4vW0nDbKGaoWbOKRnWnTMIwhIGlcU4GZ
+pX7o+uKpZK6hL5O2WV3Cv6qE
+-----END AGE ENCRYPTED FILE-----
+`
+	assert.Equal([]byte("hello ICON"), testAgeDecrypt(t, identity, produced))
+}
+
+func testAgeRecipient(t *testing.T, identity []byte) string {
+	publicKey, err := curve25519.X25519(identity, curve25519.Basepoint)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return testBech32Encode("age", publicKey)
+}
+
+func testBech32Encode(hrp string, data []byte) string {
+	var values []byte
+	acc, bits := 0, 0
+	for _, b := range data {
+		acc = acc<<8 | int(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	polymod := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		values = append(values, byte(polymod>>uint(5*(5-i)))&31)
+	}
+	encoded := hrp + "1"
+	for _, v := range values {
+		encoded += string(bech32Charset[v])
+	}
+	return encoded
+}
+
+// testAgeDecrypt decrypts a single X25519 recipient, single chunk age file.
+func testAgeDecrypt(t *testing.T, identity []byte, armored string) []byte {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != ageArmorType {
+		t.Fatalf("invalid age armor")
+	}
+	lines := strings.SplitN(string(block.Bytes), "\n", 4)
+	if lines[0] != ageIntro || !strings.HasPrefix(lines[1], "-> X25519 ") || !strings.HasPrefix(lines[3], "--- ") {
+		t.Fatalf("invalid age header")
+	}
+	b64 := base64.RawStdEncoding
+	ephemeralShare, _ := b64.DecodeString(strings.TrimPrefix(lines[1], "-> X25519 "))
+	wrappedKey, _ := b64.DecodeString(lines[2])
+	mac, _ := b64.DecodeString(lines[3][4:47])
+	body := []byte(lines[3][48:])
+
+	recipientKey, _ := curve25519.X25519(identity, curve25519.Basepoint)
+	sharedSecret, _ := curve25519.X25519(identity, ephemeralShare)
+	wrappingKey, _ := hkdfSHA256(sharedSecret, append(ephemeralShare, recipientKey...), ageX25519Label)
+	aead, _ := chacha20poly1305.New(wrappingKey)
+	fileKey, err := aead.Open(nil, make([]byte, 12), wrappedKey, nil)
+	if err != nil {
+		t.Fatalf("failed to unwrap the file key: %v", err)
+	}
+
+	macKey, _ := hkdfSHA256(fileKey, nil, "header")
+	h := hmac.New(sha256.New, macKey)
+	h.Write([]byte(strings.Join(lines[:3], "\n") + "\n---"))
+	if !hmac.Equal(mac, h.Sum(nil)) {
+		t.Fatalf("invalid header MAC")
+	}
+
+	payloadKey, _ := hkdfSHA256(fileKey, body[:16], "payload")
+	aead, _ = chacha20poly1305.New(payloadKey)
+	nonce := make([]byte, 12)
+	nonce[11] = 1
+	plain, err := aead.Open(nil, nonce, body[16:], nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt the payload: %v", err)
+	}
+	return plain
+}
@@ -0,0 +1,260 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// BLS signatures use the minimal-pubkey-size proof of possession scheme of
+// draft-irtf-cfrg-bls-signature: 48-byte G1 public keys and 96-byte G2 signatures.
+const (
+	BLSSignatureDST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	BLSPopDST       = "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+	BLSPrivateKeyLen = 32
+	BLSPublicKeyLen  = 48
+	BLSSignatureLen  = 96
+)
+
+var blsOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// BLSPrivateKey is a BLS12-381 secret scalar
+type BLSPrivateKey struct {
+	scalar *bls12381.Fr
+}
+
+// GenerateBLSKey returns a new random BLS12-381 key.
+func GenerateBLSKey() (*BLSPrivateKey, error) {
+	for {
+		// 48 bytes reduced by the order keep the bias negligible
+		buf := make([]byte, 48)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).Mod(new(big.Int).SetBytes(buf), blsOrder)
+		if k.Sign() != 0 {
+			return ParseBLSPrivateKey(k.FillBytes(make([]byte, BLSPrivateKeyLen)))
+		}
+	}
+}
+
+// ParseBLSPrivateKey parses the 32-byte big-endian scalar.
+func ParseBLSPrivateKey(b []byte) (*BLSPrivateKey, error) {
+	if len(b) != BLSPrivateKeyLen {
+		return nil, fmt.Errorf("BLS private key must be %d bytes - input: %d", BLSPrivateKeyLen, len(b))
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Sign() == 0 || k.Cmp(blsOrder) >= 0 {
+		return nil, errors.New("BLS private key is out of range")
+	}
+	return &BLSPrivateKey{scalar: bls12381.NewFr().FromBytes(b)}, nil
+}
+
+// ParseBLSPrivateKeyFromString parses the hex encoded scalar.
+func ParseBLSPrivateKeyFromString(s string) (*BLSPrivateKey, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLS private key hex")
+	}
+	return ParseBLSPrivateKey(b)
+}
+
+func (k *BLSPrivateKey) Bytes() []byte {
+	return k.scalar.ToBytes()
+}
+
+func (k *BLSPrivateKey) String() string {
+	return hex.EncodeToString(k.Bytes())
+}
+
+// PublicKey returns the compressed G1 public key.
+func (k *BLSPrivateKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToCompressed(g1.MulScalar(g1.New(), g1.One(), k.scalar))
+}
+
+// Sign signs the message with the signature DST.
+func (k *BLSPrivateKey) Sign(message []byte) ([]byte, error) {
+	return k.sign(message, BLSSignatureDST)
+}
+
+// ProofOfPossession signs the public key with the proof of possession DST.
+func (k *BLSPrivateKey) ProofOfPossession() ([]byte, error) {
+	return k.sign(k.PublicKey(), BLSPopDST)
+}
+
+func (k *BLSPrivateKey) sign(message []byte, dst string) ([]byte, error) {
+	g2 := bls12381.NewG2()
+	h, err := g2.HashToCurve(message, []byte(dst))
+	if err != nil {
+		return nil, err
+	}
+	return g2.ToCompressed(g2.MulScalar(g2.New(), h, k.scalar)), nil
+}
+
+// VerifyBLS verifies the signature of the message with the signature DST.
+func VerifyBLS(publicKey, message, signature []byte) bool {
+	return verifyBLS(publicKey, message, signature, BLSSignatureDST)
+}
+
+// VerifyBLSProofOfPossession verifies the proof of possession of the public key.
+func VerifyBLSProofOfPossession(publicKey, proof []byte) bool {
+	return verifyBLS(publicKey, publicKey, proof, BLSPopDST)
+}
+
+func verifyBLS(publicKey, message, signature []byte, dst string) bool {
+	engine := bls12381.NewEngine()
+	pk, err := engine.G1.FromCompressed(publicKey)
+	if err != nil || engine.G1.IsZero(pk) || !engine.G1.InCorrectSubgroup(pk) {
+		return false
+	}
+	sig, err := engine.G2.FromCompressed(signature)
+	if err != nil || !engine.G2.InCorrectSubgroup(sig) {
+		return false
+	}
+	h, err := engine.G2.HashToCurve(message, []byte(dst))
+	if err != nil {
+		return false
+	}
+	// e(pk, H(m)) == e(g1, sig)
+	engine.AddPair(pk, h)
+	engine.AddPairInv(engine.G1.One(), sig)
+	return engine.Check()
+}
+
+// BLSKeyID returns the identifier a BLS account is stored under, the 0x
+// prefixed hex of its compressed public key. It cannot be taken for a wallet
+// address.
+func BLSKeyID(publicKey []byte) string {
+	return "0x" + hex.EncodeToString(publicKey)
+}
+
+func (b *backend) createBLSAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	nameInput := data.Get("name").(string)
+	keyInput := data.Get("privateKey").(string)
+
+	var privateKey *BLSPrivateKey
+	var err error
+	if keyInput != "" {
+		if privateKey, err = ParseBLSPrivateKeyFromString(keyInput); err != nil {
+			b.Logger().Error("Input BLS private key did not parse successfully", "error", err)
+			return nil, err
+		}
+	} else if privateKey, err = GenerateBLSKey(); err != nil {
+		return nil, err
+	}
+	publicKey := privateKey.PublicKey()
+	address := BLSKeyID(publicKey)
+	b.Logger().Info("Load BLS key", "address", address, "publicKey", hex.EncodeToString(publicKey))
+
+	stored, err := b.checkAccountConflict(ctx, req, address, nameInput, data.Get("overwrite").(bool))
+	if err != nil {
+		return nil, err
+	}
+	account := &Account{
+		Address:    address,
+		PrivateKey: privateKey.String(),
+		PublicKey:  hex.EncodeToString(publicKey),
+		AliasName:  nameInput,
+		KeyType:    KeyTypeBLS12381,
+		Exportable: data.Get("exportable").(bool),
+	}
+	account.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"alias_name": account.AliasName,
+			"key_type":   account.KeyType,
+			"public_key": "0x" + account.PublicKey,
+		},
+	}, nil
+}
+
+// retrieveBLSKey returns the key of an active BLS account.
+func (b *backend) retrieveBLSKey(ctx context.Context, req *logical.Request, name string) (*Account, *BLSPrivateKey, error) {
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account == nil {
+		return nil, nil, fmt.Errorf("Signing account %s does not exist", name)
+	}
+	if !account.IsActive() {
+		return nil, nil, fmt.Errorf("Signing account %s is %s", name, account.Status)
+	}
+	if account.KeyType != KeyTypeBLS12381 {
+		return nil, nil, fmt.Errorf("Signing account %s is not a %s key", name, KeyTypeBLS12381)
+	}
+	privateKey, err := ParseBLSPrivateKeyFromString(account.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, privateKey, nil
+}
+
+func (b *backend) signBLS(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	message, err := hex.DecodeString(strings.TrimPrefix(data.Get("message").(string), "0x"))
+	if err != nil || len(message) == 0 {
+		return nil, fmt.Errorf("message must be a non-empty hex string")
+	}
+	account, privateKey, err := b.retrieveBLSKey(ctx, req, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := privateKey.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[SIGN][OK] BLS signature", "address", account.Address)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"public_key": "0x" + account.PublicKey,
+			"signature":  "0x" + hex.EncodeToString(signature),
+		},
+	}, nil
+}
+
+func (b *backend) proveBLSPossession(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	account, privateKey, err := b.retrieveBLSKey(ctx, req, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	proof, err := privateKey.ProofOfPossession()
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":             account.Address,
+			"public_key":          "0x" + account.PublicKey,
+			"proof_of_possession": "0x" + hex.EncodeToString(proof),
+		},
+	}, nil
+}
@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBLSSign(t *testing.T) {
+	assert := assert.New(t)
+	// Ethereum consensus spec vector, which uses the same ciphersuite
+	privateKey, err := ParseBLSPrivateKeyFromString("0x263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	message := make([]byte, 32)
+	signature, err := privateKey.Sign(message)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55", hex.EncodeToString(signature))
+	assert.Equal("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a", hex.EncodeToString(privateKey.PublicKey()))
+	assert.True(VerifyBLS(privateKey.PublicKey(), message, signature))
+	message[0] = 1
+	assert.False(VerifyBLS(privateKey.PublicKey(), message, signature))
+
+	// the proof of possession and the signature of a non-empty message were
+	// produced with blst, using the DSTs of the proof of possession ciphersuite
+	signature, err = privateKey.Sign([]byte("hello ICON"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("8d17ae89ed8433019bf9d17c6a9b3647e4e18dbc97451d701c4b01ea951fc802826884d002dbe2536332c19707b1f2731235d3d9b16f74f6f20cc73c894d8effe9a1f9f91b5d0e991bc5538409bd475eb7163e75fde84b019710ee9f4637ee36", hex.EncodeToString(signature))
+
+	proof, err := privateKey.ProofOfPossession()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("b803eb0ed93ea10224a73b6b9c725796be9f5fefd215ef7a5b97234cc956cf6870db6127b7e4d824ec62276078e787db05584ce1adbf076bc0808ca0f15b73d59060254b25393d95dfc7abe3cda566842aaedf50bbb062aae1bbb6ef3b1f77e1", hex.EncodeToString(proof))
+	assert.True(VerifyBLSProofOfPossession(privateKey.PublicKey(), proof))
+	// a proof of possession is not a signature of the public key
+	assert.False(VerifyBLS(privateKey.PublicKey(), privateKey.PublicKey(), proof))
+}
+
+func TestBLSAccount(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":     "prep-node",
+		"key_type": KeyTypeBLS12381,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+	publicKey, _ := hex.DecodeString(strings.TrimPrefix(resp.Data["public_key"].(string), "0x"))
+	assert.Equal(BLSPublicKeyLen, len(publicKey))
+	assert.Equal("0x"+hex.EncodeToString(publicKey), address)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/prep-node")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(KeyTypeBLS12381, resp.Data["key_type"])
+
+	// the account is also found by its ID, which is not an ICON address
+	assert.False(IsValidIconAddress(address))
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+strings.ToUpper(address[2:]))
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.NotNil(err)
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address)
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("prep-node", resp.Data["alias_name"])
+	assert.EqualError(validateAlias(address), "alias must not be formatted as an address - "+address)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/prep-node/bls/pop")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	proof, _ := hex.DecodeString(strings.TrimPrefix(resp.Data["proof_of_possession"].(string), "0x"))
+	assert.True(VerifyBLSProofOfPossession(publicKey, proof))
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/prep-node/bls/sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"message": "0x68656c6c6f"}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	signature, _ := hex.DecodeString(strings.TrimPrefix(resp.Data["signature"].(string), "0x"))
+	assert.Equal(BLSSignatureLen, len(signature))
+	assert.True(VerifyBLS(publicKey, []byte("hello"), signature))
+
+	// a BLS key cannot sign ICON transactions, nor a wallet key BLS messages
+	err = signWithAccount(t, b, storage, "prep-node")
+	assert.Equal("Invalid 'address' value=prep-node, len=9", err.Error())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "wallet"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/wallet/bls/pop")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("Signing account wallet is not a bls12-381 key", err.Error())
+}
+
+func TestBLSAccountImport(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"key_type":   KeyTypeBLS12381,
+		"privateKey": "0x263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("0xa491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a", resp.Data["public_key"])
+
+	// the order of the group is out of range
+	req.Data["privateKey"] = "73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001"
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("BLS private key is out of range", err.Error())
+
+	req.Data["key_type"] = "ed25519"
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("unsupported key_type - ed25519", err.Error())
+}
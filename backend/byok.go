@@ -0,0 +1,284 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// WrappingKeyBits is the size of the RSA key used to wrap imported keys
+	WrappingKeyBits = 4096
+
+	wrappingKeyPath = "config/wrapping_key"
+)
+
+var kwpIV = []byte{0xA6, 0x59, 0x59, 0xA6}
+
+// wrappingKeyEntry is the stored RSA wrapping key in PKCS#1 DER
+type wrappingKeyEntry struct {
+	Key []byte `json:"key"`
+}
+
+// getWrappingKey returns the RSA wrapping key, generating it on first use.
+func (b *backend) getWrappingKey(ctx context.Context, req *logical.Request) (*rsa.PrivateKey, error) {
+	b.wrappingKeyLock.Lock()
+	defer b.wrappingKeyLock.Unlock()
+
+	entry, err := req.Storage.Get(ctx, wrappingKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		var stored wrappingKeyEntry
+		if err := entry.DecodeJSON(&stored); err != nil {
+			return nil, err
+		}
+		return x509.ParsePKCS1PrivateKey(stored.Key)
+	}
+
+	b.Logger().Info("Generate new wrapping key", "bits", WrappingKeyBits)
+	key, err := rsa.GenerateKey(rand.Reader, WrappingKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	entry, err = logical.StorageEntryJSON(wrappingKeyPath, &wrappingKeyEntry{Key: x509.MarshalPKCS1PrivateKey(key)})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the wrapping key to storage", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+// UnwrapKey decrypts a key wrapped as RSA-OAEP(ephemeral AES key) | AES-KWP(key),
+// the format used by the Vault transit BYOK import.
+func UnwrapKey(wrappingKey *rsa.PrivateKey, ciphertext []byte, hash crypto.Hash) ([]byte, error) {
+	rsaLen := wrappingKey.Size()
+	if len(ciphertext) <= rsaLen {
+		return nil, fmt.Errorf("wrapped key is too short - %d bytes", len(ciphertext))
+	}
+	ephemeralKey, err := rsa.DecryptOAEP(hash.New(), rand.Reader, wrappingKey, ciphertext[:rsaLen], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the ephemeral key: %v", err)
+	}
+	return KWPUnwrap(ephemeralKey, ciphertext[rsaLen:])
+}
+
+// WrapKey is the inverse of UnwrapKey, for clients of the plugin.
+func WrapKey(wrappingKey *rsa.PublicKey, key []byte, hash crypto.Hash) ([]byte, error) {
+	ephemeralKey := make([]byte, 32)
+	if _, err := rand.Read(ephemeralKey); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(hash.New(), rand.Reader, wrappingKey, ephemeralKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := KWPWrap(ephemeralKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(encryptedKey, wrapped...), nil
+}
+
+// KWPWrap wraps the key with AES Key Wrap with Padding (RFC 5649).
+func KWPWrap(kek, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, errors.New("key to wrap is empty")
+	}
+	n := (len(key) + 7) / 8
+	aiv := make([]byte, 8)
+	copy(aiv, kwpIV)
+	binary.BigEndian.PutUint32(aiv[4:], uint32(len(key)))
+	padded := make([]byte, n*8)
+	copy(padded, key)
+
+	if n == 1 {
+		out := make([]byte, 16)
+		block.Encrypt(out, append(aiv, padded...))
+		return out, nil
+	}
+
+	a := aiv
+	r := padded
+	buf := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(buf, a)
+			copy(buf[8:], r[i*8:(i+1)*8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^t)
+			copy(r[i*8:(i+1)*8], buf[8:])
+		}
+	}
+	return append(a, r...), nil
+}
+
+// KWPUnwrap unwraps a key wrapped with AES Key Wrap with Padding (RFC 5649).
+func KWPUnwrap(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < 16 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("invalid wrapped key length - %d", len(wrapped))
+	}
+	n := len(wrapped)/8 - 1
+
+	var a, r []byte
+	if n == 1 {
+		out := make([]byte, 16)
+		block.Decrypt(out, wrapped)
+		a, r = out[:8], out[8:]
+	} else {
+		a = make([]byte, 8)
+		copy(a, wrapped[:8])
+		r = make([]byte, n*8)
+		copy(r, wrapped[8:])
+		buf := make([]byte, 16)
+		for j := 5; j >= 0; j-- {
+			for i := n - 1; i >= 0; i-- {
+				t := uint64(n*j + i + 1)
+				binary.BigEndian.PutUint64(buf, binary.BigEndian.Uint64(a)^t)
+				copy(buf[8:], r[i*8:(i+1)*8])
+				block.Decrypt(buf, buf)
+				copy(a, buf[:8])
+				copy(r[i*8:(i+1)*8], buf[8:])
+			}
+		}
+	}
+
+	if subtle.ConstantTimeCompare(a[:4], kwpIV) != 1 {
+		return nil, errors.New("integrity check failed, wrong key or corrupted data")
+	}
+	mli := int(binary.BigEndian.Uint32(a[4:]))
+	if mli <= 8*(n-1) || mli > 8*n {
+		return nil, errors.New("integrity check failed, invalid message length")
+	}
+	for _, p := range r[mli:] {
+		if p != 0 {
+			return nil, errors.New("integrity check failed, invalid padding")
+		}
+	}
+	return r[:mli], nil
+}
+
+func parseWrappingHash(name string) (crypto.Hash, error) {
+	switch strings.ToUpper(name) {
+	case "SHA1":
+		return crypto.SHA1, nil
+	case "SHA224":
+		return crypto.SHA224, nil
+	case "SHA256":
+		return crypto.SHA256, nil
+	case "SHA384":
+		return crypto.SHA384, nil
+	case "SHA512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported hash_function - %s", name)
+}
+
+func (b *backend) readWrappingKey(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	key, err := b.getWrappingKey(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		},
+	}, nil
+}
+
+func (b *backend) importWrappedKey(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	nameInput := data.Get("name").(string)
+	exportable := data.Get("exportable").(bool)
+	ciphertext, err := base64.StdEncoding.DecodeString(data.Get("ciphertext").(string))
+	if err != nil || len(ciphertext) == 0 {
+		return nil, fmt.Errorf("ciphertext must be a base64 encoded wrapped key")
+	}
+	hash, err := parseWrappingHash(data.Get("hash_function").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	wrappingKey, err := b.getWrappingKey(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := UnwrapKey(wrappingKey, ciphertext, hash)
+	if err != nil {
+		b.Logger().Error("[IMPORT][FAIL] Failed to unwrap the key", "error", err)
+		return nil, err
+	}
+	privateKey, err := ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapped key is not a valid secp256k1 private key: %v", err)
+	}
+	publicKey := privateKey.PublicKey()
+	stored, err := b.checkAccountConflict(ctx, req, publicKey.Address(), nameInput, data.Get("overwrite").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Address:    publicKey.Address(),
+		PrivateKey: privateKey.String(),
+		PublicKey:  publicKey.String(),
+		AliasName:  nameInput,
+		Exportable: exportable,
+	}
+	account.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[IMPORT][OK] Imported wrapped key", "address", account.Address)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"alias_name": account.AliasName,
+			"public_key": account.PublicKey,
+		},
+	}, nil
+}
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKWP(t *testing.T) {
+	// RFC 5649 section 6
+	kek, _ := hex.DecodeString("5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8")
+	vectors := map[string]string{
+		"c37b7e6492584340bed12207808941155068f738": "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		"466f7250617369": "afbeb0f07dfbf5419200f2ccb50bb24f",
+	}
+	for key, expected := range vectors {
+		keyBytes, _ := hex.DecodeString(key)
+		wrapped, err := KWPWrap(kek, keyBytes)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assert.Equal(t, expected, hex.EncodeToString(wrapped))
+
+		unwrapped, err := KWPUnwrap(kek, wrapped)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assert.Equal(t, key, hex.EncodeToString(unwrapped))
+
+		wrapped[len(wrapped)-1] ^= 0x01
+		_, err = KWPUnwrap(kek, wrapped)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestImportWrappedKey(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.ReadOperation, "wrapping_key")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	publicKeyPEM := resp.Data["public_key"].(string)
+
+	// the wrapping key is generated once
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(publicKeyPEM, resp.Data["public_key"])
+
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyBytes, _ := hex.DecodeString("d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32")
+	wrapped, err := WrapKey(publicKey.(*rsa.PublicKey), keyBytes, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "import/wrapped")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"ciphertext":    base64.StdEncoding.EncodeToString(wrapped),
+		"hash_function": "SHA1",
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.NotNil(err)
+
+	req.Data["hash_function"] = "SHA256"
+	req.Data["name"] = "byok"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("hx10b1c9aee600db8eea807c77b5040ab294cdcf99", resp.Data["address"])
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/hx10b1c9aee600db8eea807c77b5040ab294cdcf99")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("byok", resp.Data["alias_name"])
+}
@@ -0,0 +1,620 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// DefaultCertificateMaxTTL is the maximum lifetime of an issued certificate, 30 days
+	DefaultCertificateMaxTTL = 30 * 24 * 60 * 60
+	// certificateBackdate allows for the clock skew of the relying parties
+	certificateBackdate = 30 * time.Second
+	// caCertificateLifetime is the validity of the CA certificate of an account, 10 years
+	caCertificateLifetime = 10 * 365 * 24 * time.Hour
+)
+
+var (
+	oidSignatureECDSAWithSHA256  = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidExtensionRequest          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+	oidExtensionSubjectKeyId     = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionAuthorityKeyId   = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtKeyUsageServerAuth     = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 1}
+	oidExtKeyUsageClientAuth     = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}
+)
+
+// key usage bits of RFC 5280
+const (
+	keyUsageDigitalSignature = 0
+	keyUsageCertSign         = 5
+	keyUsageCRLSign          = 6
+)
+
+// certificationRequestInfo is the signed part of a PKCS#10 request
+type certificationRequestInfo struct {
+	Raw        asn1.RawContent
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes []asn1.RawValue `asn1:"tag:0"`
+}
+
+// extensionRequest is the PKCS#9 attribute carrying the requested extensions
+type extensionRequest struct {
+	Type   asn1.ObjectIdentifier
+	Values [][]pkix.Extension `asn1:"set"`
+}
+
+// signedData is the outer structure of both certificates and requests
+type signedData struct {
+	Raw                asn1.RawContent
+	Data               asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+}
+
+type tbsCertificate struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"explicit,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           certificateValidity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	Extensions         []pkix.Extension `asn1:"optional,explicit,tag:3"`
+}
+
+type certificateValidity struct {
+	NotBefore, NotAfter time.Time
+}
+
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+type authorityKeyId struct {
+	Id []byte `asn1:"optional,tag:0"`
+}
+
+// certificateRequest is a parsed PKCS#10 request whose signature was checked
+type certificateRequest struct {
+	subject       []byte
+	publicKeyInfo []byte
+	keyId         []byte
+	// subjectAltName is the requested SAN extension, if any
+	subjectAltName *pkix.Extension
+}
+
+// signDER signs the DER data with ECDSA-with-SHA256 and returns the outer
+// structure of a certificate or a request.
+func signDER(data []byte, privateKey *PrivateKey) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	sig, err := NewSignature(hash[:], privateKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := sig.SerializeDER()
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(signedData{
+		Data:               asn1.RawValue{FullBytes: data},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256},
+		Signature:          asn1.BitString{Bytes: der, BitLength: 8 * len(der)},
+	})
+}
+
+// subjectKeyId is the SHA-1 of the public key bits, as in RFC 5280 4.2.1.2.
+func subjectKeyId(publicKeyInfo []byte) ([]byte, error) {
+	var info subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(publicKeyInfo, &info); err != nil {
+		return nil, err
+	}
+	id := sha1.Sum(info.PublicKey.RightAlign())
+	return id[:], nil
+}
+
+func marshalKeyUsage(bits ...int) (pkix.Extension, error) {
+	usage := make([]byte, 1)
+	length := 0
+	for _, bit := range bits {
+		usage[0] |= 0x80 >> uint(bit)
+		if bit+1 > length {
+			length = bit + 1
+		}
+	}
+	value, err := asn1.Marshal(asn1.BitString{Bytes: usage, BitLength: length})
+	return pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value}, err
+}
+
+// marshalSubjectAltName returns the SAN extension of the names, or nil if there are none.
+func marshalSubjectAltName(dnsNames, emailAddresses, ipAddresses, uris []string) (*pkix.Extension, error) {
+	var names []asn1.RawValue
+	for _, name := range dnsNames {
+		names = append(names, asn1.RawValue{Tag: 2, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range emailAddresses {
+		names = append(names, asn1.RawValue{Tag: 1, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, uri := range uris {
+		if _, err := url.Parse(uri); err != nil {
+			return nil, fmt.Errorf("invalid URI SAN %s", uri)
+		}
+		names = append(names, asn1.RawValue{Tag: 6, Class: asn1.ClassContextSpecific, Bytes: []byte(uri)})
+	}
+	for _, ip := range ipAddresses {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return nil, fmt.Errorf("invalid IP SAN %s", ip)
+		}
+		if v4 := parsed.To4(); v4 != nil {
+			parsed = v4
+		}
+		names = append(names, asn1.RawValue{Tag: 7, Class: asn1.ClassContextSpecific, Bytes: parsed})
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	value, err := asn1.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+	return &pkix.Extension{Id: oidExtensionSubjectAltName, Value: value}, nil
+}
+
+// CreateCertificateRequest returns a DER PKCS#10 request of the subject and
+// the SAN extension, signed with the secp256k1 key.
+func CreateCertificateRequest(subject pkix.Name, subjectAltName *pkix.Extension, privateKey *PrivateKey) ([]byte, error) {
+	rawSubject, err := asn1.Marshal(subject.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+	publicKeyInfo, err := privateKey.PublicKey().MarshalPKIX()
+	if err != nil {
+		return nil, err
+	}
+	var attributes []asn1.RawValue
+	if subjectAltName != nil {
+		attribute, err := asn1.Marshal(extensionRequest{
+			Type:   oidExtensionRequest,
+			Values: [][]pkix.Extension{{*subjectAltName}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, asn1.RawValue{FullBytes: attribute})
+	}
+	info, err := asn1.Marshal(certificationRequestInfo{
+		Subject:    asn1.RawValue{FullBytes: rawSubject},
+		PublicKey:  asn1.RawValue{FullBytes: publicKeyInfo},
+		Attributes: attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signDER(info, privateKey)
+}
+
+// checkSecp256k1Signature verifies an ECDSA-with-SHA256 signature over the
+// data with the key of the SubjectPublicKeyInfo. Unlike Signature.Verify, it
+// accepts high-S signatures, which most X.509 tools produce.
+func checkSecp256k1Signature(publicKeyInfo []byte, request *signedData) error {
+	if !request.SignatureAlgorithm.Algorithm.Equal(oidSignatureECDSAWithSHA256) {
+		return errors.New("secp256k1 requests must be signed with ecdsa-with-SHA256")
+	}
+	publicKey, err := ParsePKIXPublicKey(publicKeyInfo)
+	if err != nil {
+		return err
+	}
+	pub, err := secp256k1.ParsePubKey(publicKey.bytes)
+	if err != nil {
+		return err
+	}
+	sig, err := ecdsa.ParseDERSignature(request.Signature.RightAlign())
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(request.Data.FullBytes)
+	if !sig.Verify(hash[:], pub) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// ParseCertificateRequest parses a DER PKCS#10 request and checks its
+// signature. secp256k1 requests are handled here, as crypto/x509 does not
+// support the curve, and the others by crypto/x509.
+func ParseCertificateRequest(der []byte) (*certificateRequest, error) {
+	var request signedData
+	if rest, err := asn1.Unmarshal(der, &request); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after the certificate request")
+	}
+	var info certificationRequestInfo
+	if _, err := asn1.Unmarshal(request.Data.FullBytes, &info); err != nil {
+		return nil, err
+	}
+	if _, err := ParsePKIXPublicKey(info.PublicKey.FullBytes); err == nil {
+		if err := checkSecp256k1Signature(info.PublicKey.FullBytes, &request); err != nil {
+			return nil, fmt.Errorf("invalid certificate request signature - %v", err)
+		}
+	} else {
+		parsed, err := x509.ParseCertificateRequest(der)
+		if err != nil {
+			return nil, err
+		}
+		if err := parsed.CheckSignature(); err != nil {
+			return nil, fmt.Errorf("invalid certificate request signature - %v", err)
+		}
+	}
+
+	keyId, err := subjectKeyId(info.PublicKey.FullBytes)
+	if err != nil {
+		return nil, err
+	}
+	csr := &certificateRequest{
+		subject:       info.Subject.FullBytes,
+		publicKeyInfo: info.PublicKey.FullBytes,
+		keyId:         keyId,
+	}
+	for _, raw := range info.Attributes {
+		var attribute extensionRequest
+		if _, err := asn1.Unmarshal(raw.FullBytes, &attribute); err != nil || !attribute.Type.Equal(oidExtensionRequest) {
+			continue
+		}
+		for _, extensions := range attribute.Values {
+			for i := range extensions {
+				if extensions[i].Id.Equal(oidExtensionSubjectAltName) {
+					csr.subjectAltName = &extensions[i]
+				}
+			}
+		}
+	}
+	return csr, nil
+}
+
+// issuerName is the name of an account as an issuing CA
+func issuerName(address string) ([]byte, error) {
+	return asn1.Marshal(pkix.Name{CommonName: address}.ToRDNSequence())
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// createCertificate signs a v3 certificate with the issuer key.
+func createCertificate(tbs *tbsCertificate, privateKey *PrivateKey) ([]byte, error) {
+	tbs.Version = 2
+	tbs.SignatureAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidSignatureECDSAWithSHA256}
+	data, err := asn1.Marshal(*tbs)
+	if err != nil {
+		return nil, err
+	}
+	return signDER(data, privateKey)
+}
+
+// issuerKeyIds returns the subject key ID of the account key, as its DER
+// value and as the authority key ID extension value of the issued certificates.
+func issuerKeyIds(privateKey *PrivateKey) ([]byte, []byte, []byte, error) {
+	issuerKeyInfo, err := privateKey.PublicKey().MarshalPKIX()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyId, err := subjectKeyId(issuerKeyInfo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyIdValue, err := asn1.Marshal(keyId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	authorityKeyIdValue, err := asn1.Marshal(authorityKeyId{Id: keyId})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return issuerKeyInfo, keyIdValue, authorityKeyIdValue, nil
+}
+
+// CreateCACertificate returns the self-signed CA certificate of the account
+// key, valid over the given period.
+func CreateCACertificate(address string, privateKey *PrivateKey, notBefore, notAfter time.Time) ([]byte, error) {
+	issuer, err := issuerName(address)
+	if err != nil {
+		return nil, err
+	}
+	issuerKeyInfo, keyIdValue, _, err := issuerKeyIds(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyUsage, err := marshalKeyUsage(keyUsageDigitalSignature, keyUsageCertSign, keyUsageCRLSign)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := asn1.Marshal(basicConstraints{IsCA: true, MaxPathLen: 0})
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	return createCertificate(&tbsCertificate{
+		SerialNumber: serial,
+		Issuer:       asn1.RawValue{FullBytes: issuer},
+		Validity: certificateValidity{
+			NotBefore: notBefore.UTC().Truncate(time.Second),
+			NotAfter:  notAfter.UTC().Truncate(time.Second),
+		},
+		Subject:   asn1.RawValue{FullBytes: issuer},
+		PublicKey: asn1.RawValue{FullBytes: issuerKeyInfo},
+		Extensions: []pkix.Extension{
+			keyUsage,
+			{Id: oidExtensionBasicConstraints, Critical: true, Value: constraints},
+			{Id: oidExtensionSubjectKeyId, Value: keyIdValue},
+		},
+	}, privateKey)
+}
+
+// SignCertificate issues a TLS certificate for the request under the CA
+// certificate of the account key.
+func SignCertificate(csr *certificateRequest, address string, privateKey *PrivateKey, notBefore, notAfter time.Time) ([]byte, *big.Int, error) {
+	issuer, err := issuerName(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _, authorityKeyIdValue, err := issuerKeyIds(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyUsage, err := marshalKeyUsage(keyUsageDigitalSignature)
+	if err != nil {
+		return nil, nil, err
+	}
+	constraints, err := asn1.Marshal(basicConstraints{MaxPathLen: -1})
+	if err != nil {
+		return nil, nil, err
+	}
+	extKeyUsage, err := asn1.Marshal([]asn1.ObjectIdentifier{oidExtKeyUsageClientAuth, oidExtKeyUsageServerAuth})
+	if err != nil {
+		return nil, nil, err
+	}
+	keyIdValue, err := asn1.Marshal(csr.keyId)
+	if err != nil {
+		return nil, nil, err
+	}
+	extensions := []pkix.Extension{
+		keyUsage,
+		{Id: oidExtensionExtendedKeyUsage, Value: extKeyUsage},
+		{Id: oidExtensionBasicConstraints, Critical: true, Value: constraints},
+		{Id: oidExtensionSubjectKeyId, Value: keyIdValue},
+		{Id: oidExtensionAuthorityKeyId, Value: authorityKeyIdValue},
+	}
+	if csr.subjectAltName != nil {
+		extensions = append(extensions, *csr.subjectAltName)
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	certificate, err := createCertificate(&tbsCertificate{
+		SerialNumber: serial,
+		Issuer:       asn1.RawValue{FullBytes: issuer},
+		Validity: certificateValidity{
+			NotBefore: notBefore.UTC().Truncate(time.Second),
+			NotAfter:  notAfter.UTC().Truncate(time.Second),
+		},
+		Subject:    asn1.RawValue{FullBytes: csr.subject},
+		PublicKey:  asn1.RawValue{FullBytes: csr.publicKeyInfo},
+		Extensions: extensions,
+	}, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, serial, nil
+}
+
+// serialNumberString formats a serial number as colon-separated hex, as Vault PKI does.
+func serialNumberString(serial *big.Int) string {
+	raw := serial.Bytes()
+	out := make([]byte, 0, 3*len(raw))
+	for i, b := range raw {
+		if i > 0 {
+			out = append(out, ':')
+		}
+		out = append(out, hex.EncodeToString([]byte{b})...)
+	}
+	return string(out)
+}
+
+func (b *backend) createCSR(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+	subject := pkix.Name{
+		CommonName:         data.Get("common_name").(string),
+		Organization:       data.Get("organization").([]string),
+		OrganizationalUnit: data.Get("organizational_unit").([]string),
+		Country:            data.Get("country").([]string),
+		Province:           data.Get("province").([]string),
+		Locality:           data.Get("locality").([]string),
+	}
+	if subject.CommonName == "" {
+		subject.CommonName = account.Address
+	}
+	subjectAltName, err := marshalSubjectAltName(
+		data.Get("dns_names").([]string),
+		data.Get("email_addresses").([]string),
+		data.Get("ip_sans").([]string),
+		data.Get("uri_sans").([]string),
+	)
+	if err != nil {
+		return nil, err
+	}
+	der, err := CreateCertificateRequest(subject, subjectAltName, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[CSR][OK] Created a certificate request", "address", account.Address, "common_name", subject.CommonName)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address": account.Address,
+			"csr":     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		},
+	}, nil
+}
+
+// caCertificateEntry is the stored CA certificate of an account
+type caCertificateEntry struct {
+	Certificate []byte `json:"certificate"`
+	NotAfter    int64  `json:"not_after"`
+}
+
+// accountCACertificate returns the CA certificate of the account, creating it
+// when there is none yet or when it expires before notAfter.
+func (b *backend) accountCACertificate(ctx context.Context, req *logical.Request, address string, privateKey *PrivateKey, notAfter time.Time) ([]byte, error) {
+	path := "certificates/ca/" + address
+	entry, err := req.Storage.Get(ctx, path)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the CA certificate", "address", address, "error", err)
+		return nil, err
+	}
+	if entry != nil {
+		var ca caCertificateEntry
+		if err := entry.DecodeJSON(&ca); err != nil {
+			return nil, err
+		}
+		if ca.NotAfter >= notAfter.Unix() {
+			return ca.Certificate, nil
+		}
+	}
+
+	now := time.Now()
+	caNotAfter := now.Add(caCertificateLifetime)
+	if caNotAfter.Before(notAfter) {
+		caNotAfter = notAfter
+	}
+	certificate, err := CreateCACertificate(address, privateKey, now.Add(-certificateBackdate), caNotAfter)
+	if err != nil {
+		return nil, err
+	}
+	entry, err = logical.StorageEntryJSON(path, &caCertificateEntry{
+		Certificate: certificate,
+		NotAfter:    caNotAfter.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the CA certificate", "address", address, "error", err)
+		return nil, err
+	}
+	b.Logger().Info("[CERT][OK] Created the CA certificate", "address", address, "not_after", caNotAfter.Unix())
+	return certificate, nil
+}
+
+func (b *backend) signCertificate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ttl := int64(data.Get("ttl").(int))
+	if ttl <= 0 {
+		ttl = config.CertificateMaxTTL
+	}
+	if ttl > config.CertificateMaxTTL {
+		return nil, fmt.Errorf("ttl exceeds certificate_max_ttl of %d seconds", config.CertificateMaxTTL)
+	}
+	block, _ := pem.Decode([]byte(data.Get("csr").(string)))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("csr must be a PEM CERTIFICATE REQUEST")
+	}
+	csr, err := ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	roleName := data.Get("role").(string)
+	if roleName == "" {
+		return nil, errors.New("role is required")
+	}
+	role, err := b.retrieveCertificateRole(ctx, req, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("certificate role does not exist - %s", roleName)
+	}
+	if err := role.checkRequest(csr); err != nil {
+		return nil, err
+	}
+
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	notAfter := now.Add(time.Duration(ttl) * time.Second)
+	ca, err := b.accountCACertificate(ctx, req, account.Address, privateKey, notAfter)
+	if err != nil {
+		return nil, err
+	}
+	certificate, serial, err := SignCertificate(csr, account.Address, privateKey, now.Add(-certificateBackdate), notAfter)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber := serialNumberString(serial)
+	if err := b.recordAudit(ctx, req, "sign_certificate", account.Address, map[string]interface{}{
+		"role":           role.Name,
+		"serial_number":  serialNumber,
+		"subject_key_id": hex.EncodeToString(csr.keyId),
+	}); err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"certificate":   string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+			"issuing_ca":    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca})),
+			"serial_number": serialNumber,
+			"expiration":    notAfter.Unix(),
+		},
+	}, nil
+}
@@ -0,0 +1,286 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// CertificateRole restricts the subject and the subject alternative names of
+// the certificates issued with it, like the roles of Vault PKI
+type CertificateRole struct {
+	Name string `json:"name"`
+	// AllowedDomains are the names allowed as the common name, DNS names and
+	// domains of the email addresses
+	AllowedDomains  []string `json:"allowed_domains"`
+	AllowSubdomains bool     `json:"allow_subdomains"`
+	// AllowWildcardCertificates allows *.domain host names, which also need
+	// the subdomains of the domain to be allowed
+	AllowWildcardCertificates bool `json:"allow_wildcard_certificates"`
+	AllowIPSANs               bool `json:"allow_ip_sans"`
+	// AllowedURISANs are the URIs allowed as SANs, a trailing * matches any suffix
+	AllowedURISANs []string `json:"allowed_uri_sans"`
+	// AllowedOrganizations, AllowedOrganizationalUnits, AllowedCountries,
+	// AllowedProvinces and AllowedLocalities are the O, OU, C, ST and L values
+	// allowed in the subject, which holds no other attribute than the common name
+	AllowedOrganizations       []string `json:"allowed_organizations"`
+	AllowedOrganizationalUnits []string `json:"allowed_organizational_units"`
+	AllowedCountries           []string `json:"allowed_countries"`
+	AllowedProvinces           []string `json:"allowed_provinces"`
+	AllowedLocalities          []string `json:"allowed_localities"`
+}
+
+var (
+	oidCommonName         = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidCountry            = asn1.ObjectIdentifier{2, 5, 4, 6}
+	oidLocality           = asn1.ObjectIdentifier{2, 5, 4, 7}
+	oidProvince           = asn1.ObjectIdentifier{2, 5, 4, 8}
+	oidOrganization       = asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidOrganizationalUnit = asn1.ObjectIdentifier{2, 5, 4, 11}
+)
+
+// allowsHost reports whether the host name is allowed, including a wildcard
+// in its leftmost label when the role allows wildcard certificates.
+func (r *CertificateRole) allowsHost(name string) bool {
+	if !strings.Contains(name, "*") {
+		return r.allowsName(name)
+	}
+	base := strings.TrimPrefix(name, "*.")
+	if !r.AllowWildcardCertificates || !r.AllowSubdomains || base == name || strings.Contains(base, "*") {
+		return false
+	}
+	return r.allowsName(base)
+}
+
+// allowsName reports whether the name is one of the allowed domains, or a
+// subdomain of one when subdomains are allowed.
+func (r *CertificateRole) allowsName(name string) bool {
+	if strings.Contains(name, "*") {
+		return false
+	}
+	name = strings.ToLower(name)
+	for _, domain := range r.AllowedDomains {
+		domain = strings.ToLower(domain)
+		if name == domain || (r.AllowSubdomains && strings.HasSuffix(name, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *CertificateRole) allowsURI(uri string) bool {
+	for _, pattern := range r.AllowedURISANs {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(uri, prefix) {
+				return true
+			}
+		} else if uri == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSubjectAttribute fails if the subject attribute is not allowed by the role.
+func (r *CertificateRole) checkSubjectAttribute(attribute pkix.AttributeTypeAndValue) error {
+	value, ok := attribute.Value.(string)
+	if !ok {
+		return fmt.Errorf("subject attribute %s is not a string", attribute.Type)
+	}
+	switch {
+	case attribute.Type.Equal(oidCommonName):
+		if !r.allowsHost(value) {
+			return fmt.Errorf("common name %s is not allowed by the role %s", value, r.Name)
+		}
+	case attribute.Type.Equal(oidOrganization):
+		if !containsString(r.AllowedOrganizations, value) {
+			return fmt.Errorf("organization %s is not allowed by the role %s", value, r.Name)
+		}
+	case attribute.Type.Equal(oidOrganizationalUnit):
+		if !containsString(r.AllowedOrganizationalUnits, value) {
+			return fmt.Errorf("organizational unit %s is not allowed by the role %s", value, r.Name)
+		}
+	case attribute.Type.Equal(oidCountry):
+		if !containsString(r.AllowedCountries, value) {
+			return fmt.Errorf("country %s is not allowed by the role %s", value, r.Name)
+		}
+	case attribute.Type.Equal(oidProvince):
+		if !containsString(r.AllowedProvinces, value) {
+			return fmt.Errorf("province %s is not allowed by the role %s", value, r.Name)
+		}
+	case attribute.Type.Equal(oidLocality):
+		if !containsString(r.AllowedLocalities, value) {
+			return fmt.Errorf("locality %s is not allowed by the role %s", value, r.Name)
+		}
+	default:
+		return fmt.Errorf("subject attribute %s is not allowed by the role %s", attribute.Type, r.Name)
+	}
+	return nil
+}
+
+// checkRequest fails if a subject attribute or a subject alternative name of
+// the request is not allowed by the role.
+func (r *CertificateRole) checkRequest(csr *certificateRequest) error {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(csr.subject, &rdns); err != nil || len(rest) > 0 {
+		return fmt.Errorf("invalid subject")
+	}
+	// every attribute is checked, as the subject is copied into the certificate
+	for _, rdn := range rdns {
+		for _, attribute := range rdn {
+			if err := r.checkSubjectAttribute(attribute); err != nil {
+				return err
+			}
+		}
+	}
+
+	if csr.subjectAltName == nil {
+		return nil
+	}
+	var names []asn1.RawValue
+	if rest, err := asn1.Unmarshal(csr.subjectAltName.Value, &names); err != nil || len(rest) > 0 {
+		return fmt.Errorf("invalid subject alternative names")
+	}
+	for _, name := range names {
+		if name.Class != asn1.ClassContextSpecific {
+			return fmt.Errorf("invalid subject alternative names")
+		}
+		value := string(name.Bytes)
+		switch name.Tag {
+		case 1:
+			at := strings.LastIndex(value, "@")
+			if at < 0 || !r.allowsName(value[at+1:]) {
+				return fmt.Errorf("email SAN %s is not allowed by the role %s", value, r.Name)
+			}
+		case 2:
+			if !r.allowsHost(value) {
+				return fmt.Errorf("DNS SAN %s is not allowed by the role %s", value, r.Name)
+			}
+		case 6:
+			if !r.allowsURI(value) {
+				return fmt.Errorf("URI SAN %s is not allowed by the role %s", value, r.Name)
+			}
+		case 7:
+			if !r.AllowIPSANs {
+				return fmt.Errorf("IP SAN %s is not allowed by the role %s", net.IP(name.Bytes), r.Name)
+			}
+		default:
+			return fmt.Errorf("SAN of type %d is not allowed", name.Tag)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *backend) retrieveCertificateRole(ctx context.Context, req *logical.Request, name string) (*CertificateRole, error) {
+	entry, err := req.Storage.Get(ctx, "certificate_roles/"+name)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the certificate role", "name", name, "error", err)
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	var role CertificateRole
+	if err := entry.DecodeJSON(&role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (b *backend) listCertificateRoles(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	vals, err := req.Storage.List(ctx, "certificate_roles/")
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of certificate roles", "error", err)
+		return nil, err
+	}
+	return logical.ListResponse(vals), nil
+}
+
+func (b *backend) readCertificateRole(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	role, err := b.retrieveCertificateRole(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("[READ][FAIL] Certificate role does not exist - %s", name)
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":                         role.Name,
+			"allowed_domains":              role.AllowedDomains,
+			"allow_subdomains":             role.AllowSubdomains,
+			"allow_wildcard_certificates":  role.AllowWildcardCertificates,
+			"allow_ip_sans":                role.AllowIPSANs,
+			"allowed_uri_sans":             role.AllowedURISANs,
+			"allowed_organizations":        role.AllowedOrganizations,
+			"allowed_organizational_units": role.AllowedOrganizationalUnits,
+			"allowed_countries":            role.AllowedCountries,
+			"allowed_provinces":            role.AllowedProvinces,
+			"allowed_localities":           role.AllowedLocalities,
+		},
+	}, nil
+}
+
+func (b *backend) writeCertificateRole(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	role := &CertificateRole{
+		Name:                       data.Get("name").(string),
+		AllowedDomains:             data.Get("allowed_domains").([]string),
+		AllowSubdomains:            data.Get("allow_subdomains").(bool),
+		AllowWildcardCertificates:  data.Get("allow_wildcard_certificates").(bool),
+		AllowIPSANs:                data.Get("allow_ip_sans").(bool),
+		AllowedURISANs:             data.Get("allowed_uri_sans").([]string),
+		AllowedOrganizations:       data.Get("allowed_organizations").([]string),
+		AllowedOrganizationalUnits: data.Get("allowed_organizational_units").([]string),
+		AllowedCountries:           data.Get("allowed_countries").([]string),
+		AllowedProvinces:           data.Get("allowed_provinces").([]string),
+		AllowedLocalities:          data.Get("allowed_localities").([]string),
+	}
+	entry, err := logical.StorageEntryJSON("certificate_roles/"+role.Name, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the certificate role", "name", role.Name, "error", err)
+		return nil, err
+	}
+	return b.readCertificateRole(ctx, req, data)
+}
+
+func (b *backend) deleteCertificateRole(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if err := req.Storage.Delete(ctx, "certificate_roles/"+name); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the certificate role", "name", name, "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
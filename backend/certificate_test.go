@@ -0,0 +1,316 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseTestCertificate parses a PEM certificate and checks its signature with the issuer key.
+func parseTestCertificate(t *testing.T, certificate string, issuer *PublicKey) *tbsCertificate {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		t.Fatalf("not a PEM certificate: %s", certificate)
+	}
+	var signed signedData
+	if _, err := asn1.Unmarshal(block.Bytes, &signed); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var tbs tbsCertificate
+	if _, err := asn1.Unmarshal(signed.Data.FullBytes, &tbs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.True(t, signed.SignatureAlgorithm.Algorithm.Equal(oidSignatureECDSAWithSHA256))
+	sig, err := ParseSignatureDER(signed.Signature.RightAlign())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	hash := sha256.Sum256(signed.Data.FullBytes)
+	assert.True(t, sig.Verify(hash[:], issuer))
+	return &tbs
+}
+
+func commonName(t *testing.T, raw []byte) string {
+	var rdns pkix.RDNSequence
+	if _, err := asn1.Unmarshal(raw, &rdns); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var name pkix.Name
+	name.FillFromRDNSequence(&rdns)
+	return name.CommonName
+}
+
+func TestCertificate(t *testing.T) {
+	b, storage := getBackend(t)
+	call := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Storage = storage
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+	newAccount := func() (string, *PublicKey) {
+		res, err := call("accounts", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		address := res.Data["address"].(string)
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/"+address)
+		req.Storage = storage
+		_, publicKey, err := b.(*backend).retrievePublicKey(context.Background(), req, address, "X.509")
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return address, publicKey
+	}
+	ca, caPublicKey := newAccount()
+	client, clientPublicKey := newAccount()
+
+	resp, err := call("accounts/"+client+"/csr", map[string]interface{}{
+		"organization": "ICON",
+		"dns_names":    "node.internal",
+		"ip_sans":      "10.0.0.1",
+		"uri_sans":     DID(client),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	csrPEM := resp.Data["csr"].(string)
+	block, _ := pem.Decode([]byte(csrPEM))
+	csr, err := ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, client, commonName(t, csr.subject))
+	publicKey, err := ParsePKIXPublicKey(csr.publicKeyInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, clientPublicKey.String(), publicKey.String())
+	assert.NotNil(t, csr.subjectAltName)
+
+	_, err = call("accounts/"+client+"/csr", map[string]interface{}{"ip_sans": "10.0.0"})
+	assert.EqualError(t, err, "invalid IP SAN 10.0.0")
+
+	// the names of the request must be allowed by a role
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "ttl": "1h"})
+	assert.EqualError(t, err, "role is required")
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "role": "nodes"})
+	assert.EqualError(t, err, "certificate role does not exist - nodes")
+	if _, err := call("certificate_roles/nodes", map[string]interface{}{
+		"allowed_domains":       client + ",internal",
+		"allow_subdomains":      true,
+		"allowed_organizations": "ICON",
+		"allowed_uri_sans":      "did:icon:*",
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "role": "nodes"})
+	assert.EqualError(t, err, "IP SAN 10.0.0.1 is not allowed by the role nodes")
+	if _, err := call("certificate_roles/nodes", map[string]interface{}{
+		"allowed_domains":       "internal",
+		"allow_subdomains":      true,
+		"allow_ip_sans":         true,
+		"allowed_organizations": "ICON",
+		"allowed_uri_sans":      "did:icon:*",
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "role": "nodes"})
+	assert.EqualError(t, err, "common name "+client+" is not allowed by the role nodes")
+	if _, err := call("certificate_roles/nodes", map[string]interface{}{
+		"allowed_domains":       client + ",internal",
+		"allow_subdomains":      true,
+		"allow_ip_sans":         true,
+		"allowed_organizations": "ICON",
+		"allowed_uri_sans":      "did:icon:*",
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// the account acts as the CA of a secp256k1 request
+	resp, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "ttl": "1h", "role": "nodes"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	leaf := parseTestCertificate(t, resp.Data["certificate"].(string), caPublicKey)
+	assert.Equal(t, 2, leaf.Version)
+	assert.Equal(t, ca, commonName(t, leaf.Issuer.FullBytes))
+	assert.Equal(t, client, commonName(t, leaf.Subject.FullBytes))
+	assert.Equal(t, time.Hour+certificateBackdate, leaf.Validity.NotAfter.Sub(leaf.Validity.NotBefore))
+	assert.Equal(t, serialNumberString(leaf.SerialNumber), resp.Data["serial_number"])
+	assert.Equal(t, leaf.Validity.NotAfter.Unix(), resp.Data["expiration"])
+	var hasSAN bool
+	for _, extension := range leaf.Extensions {
+		if extension.Id.Equal(oidExtensionSubjectAltName) {
+			hasSAN = true
+			assert.Equal(t, csr.subjectAltName.Value, extension.Value)
+		}
+	}
+	assert.True(t, hasSAN)
+
+	issuing := parseTestCertificate(t, resp.Data["issuing_ca"].(string), caPublicKey)
+	assert.Equal(t, issuing.Issuer.FullBytes, issuing.Subject.FullBytes)
+	assert.Equal(t, leaf.Issuer.FullBytes, issuing.Subject.FullBytes)
+	caKey, err := ParsePKIXPublicKey(issuing.PublicKey.FullBytes)
+	assert.Nil(t, err)
+	assert.Equal(t, caPublicKey.String(), caKey.String())
+	assert.Equal(t, caCertificateLifetime+certificateBackdate, issuing.Validity.NotAfter.Sub(issuing.Validity.NotBefore))
+	issuingCA := resp.Data["issuing_ca"]
+
+	// requests of other curves are checked by crypto/x509
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "validator"},
+		DNSNames: []string{"validator.internal"},
+	}, p256)
+	resp, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		"role": "nodes",
+	})
+	assert.EqualError(t, err, "common name validator is not allowed by the role nodes")
+	if _, err := call("certificate_roles/validators", map[string]interface{}{
+		"allowed_domains": "validator,validator.internal",
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		"role": "validators",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the CA certificate of the account is created once
+	assert.Equal(t, issuingCA, resp.Data["issuing_ca"])
+	block, _ = pem.Decode([]byte(resp.Data["certificate"].(string)))
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "validator", certificate.Subject.CommonName)
+	assert.Equal(t, ca, certificate.Issuer.CommonName)
+	assert.Equal(t, []string{"validator.internal"}, certificate.DNSNames)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}, certificate.ExtKeyUsage)
+	assert.Equal(t, x509.KeyUsageDigitalSignature, certificate.KeyUsage)
+	assert.False(t, certificate.IsCA)
+	assert.Equal(t, time.Duration(DefaultCertificateMaxTTL)*time.Second+certificateBackdate, certificate.NotAfter.Sub(certificate.NotBefore))
+	assert.Equal(t, p256.Public(), certificate.PublicKey)
+	sig, err := ParseSignatureDER(certificate.Signature)
+	assert.Nil(t, err)
+	hash := sha256.Sum256(certificate.RawTBSCertificate)
+	assert.True(t, sig.Verify(hash[:], caPublicKey))
+
+	// every subject attribute and wildcard name must be allowed by the role
+	p256CSR := func(subject pkix.Name, dnsNames ...string) string {
+		der, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject, DNSNames: dnsNames}, p256)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	}
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "validator", OrganizationalUnit: []string{"ops"}}),
+		"role": "validators",
+	})
+	assert.EqualError(t, err, "organizational unit ops is not allowed by the role validators")
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "validator", SerialNumber: "1"}),
+		"role": "validators",
+	})
+	assert.EqualError(t, err, "subject attribute 2.5.4.5 is not allowed by the role validators")
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "*.internal"}),
+		"role": "nodes",
+	})
+	assert.EqualError(t, err, "common name *.internal is not allowed by the role nodes")
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "node.internal"}, "*.node.internal"),
+		"role": "nodes",
+	})
+	assert.EqualError(t, err, "DNS SAN *.node.internal is not allowed by the role nodes")
+	if _, err := call("certificate_roles/wildcards", map[string]interface{}{
+		"allowed_domains":              "internal",
+		"allow_subdomains":             true,
+		"allow_wildcard_certificates":  true,
+		"allowed_organizational_units": "ops",
+		"allowed_countries":            "KR",
+		"allowed_localities":           "Seoul",
+	}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "*.internal", OrganizationalUnit: []string{"ops"}, Country: []string{"KR"}, Locality: []string{"Seoul"}}, "*.node.internal"),
+		"role": "wildcards",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	block, _ = pem.Decode([]byte(resp.Data["certificate"].(string)))
+	certificate, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "*.internal", certificate.Subject.CommonName)
+	assert.Equal(t, []string{"*.node.internal"}, certificate.DNSNames)
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{
+		"csr":  p256CSR(pkix.Name{CommonName: "node.*.internal"}),
+		"role": "wildcards",
+	})
+	assert.EqualError(t, err, "common name node.*.internal is not allowed by the role wildcards")
+
+	// a tampered request is refused
+	raw, _ := pem.Decode([]byte(csrPEM))
+	raw.Bytes[len(raw.Bytes)-1] ^= 1
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": string(pem.EncodeToMemory(raw)), "role": "nodes"})
+	assert.Contains(t, err.Error(), "invalid certificate request signature")
+
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": csrPEM, "ttl": "8760h", "role": "nodes"})
+	assert.EqualError(t, err, "ttl exceeds certificate_max_ttl of 2592000 seconds")
+	_, err = call("accounts/"+ca+"/sign_certificate", map[string]interface{}{"csr": "csr"})
+	assert.EqualError(t, err, "csr must be a PEM CERTIFICATE REQUEST")
+}
+
+func TestDEREncoding(t *testing.T) {
+	privateKey, publicKey := GenerateKey()
+	der, err := publicKey.MarshalPKIX()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parsed, err := ParsePKIXPublicKey(der)
+	assert.Nil(t, err)
+	assert.Equal(t, publicKey.String(), parsed.String())
+
+	hash := SHA3Sum256([]byte("der"))
+	sig, _ := NewSignature(hash, privateKey)
+	der, err = sig.SerializeDER()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parsedSig, err := ParseSignatureDER(der)
+	assert.Nil(t, err)
+	rs, _ := sig.SerializeRS()
+	parsedRS, _ := parsedSig.SerializeRS()
+	assert.Equal(t, rs, parsedRS)
+	assert.False(t, parsedSig.HasV())
+	assert.True(t, parsedSig.Verify(hash, publicKey))
+}
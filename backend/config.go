@@ -0,0 +1,128 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	configPath = "config/plugin"
+
+	// DefaultDeletionGracePeriod is the time a deleted account can be restored, 7 days
+	DefaultDeletionGracePeriod = 7 * 24 * 60 * 60
+)
+
+// Config is the plugin-wide configuration
+type Config struct {
+	// MaxKeyAge is the age in seconds after which an account is due for rotation. 0 disables it.
+	MaxKeyAge int64 `json:"max_key_age"`
+	// DeletionGracePeriod is the time in seconds a deleted account can be restored. 0 deletes immediately.
+	DeletionGracePeriod int64 `json:"deletion_grace_period"`
+	// AuthTimeWindow is the maximum distance in seconds between the time of an auth challenge and now
+	AuthTimeWindow int64 `json:"auth_time_window"`
+	// JWTMaxTTL is the maximum lifetime in seconds of an issued JWT
+	JWTMaxTTL int64 `json:"jwt_max_ttl"`
+	// JWTAudiences are the audiences an issued JWT can have. No JWT is issued until it is set.
+	JWTAudiences []string `json:"jwt_audiences,omitempty"`
+	// CertificateMaxTTL is the maximum lifetime in seconds of a certificate issued by an account
+	CertificateMaxTTL int64 `json:"certificate_max_ttl"`
+}
+
+func (b *backend) retrieveConfig(ctx context.Context, req *logical.Request) (*Config, error) {
+	config := &Config{
+		DeletionGracePeriod: DefaultDeletionGracePeriod,
+		AuthTimeWindow:      DefaultAuthTimeWindow,
+		JWTMaxTTL:           DefaultJWTMaxTTL,
+		CertificateMaxTTL:   DefaultCertificateMaxTTL,
+	}
+	entry, err := req.Storage.Get(ctx, configPath)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the plugin config", "error", err)
+		return nil, err
+	}
+	if entry == nil {
+		return config, nil
+	}
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (b *backend) readConfig(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"max_key_age":           config.MaxKeyAge,
+			"deletion_grace_period": config.DeletionGracePeriod,
+			"auth_time_window":      config.AuthTimeWindow,
+			"jwt_max_ttl":           config.JWTMaxTTL,
+			"jwt_audiences":         append([]string{}, config.JWTAudiences...),
+			"certificate_max_ttl":   config.CertificateMaxTTL,
+		},
+	}, nil
+}
+
+func (b *backend) writeConfig(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if maxKeyAge, ok := data.GetOk("max_key_age"); ok {
+		config.MaxKeyAge = int64(maxKeyAge.(int))
+	}
+	if gracePeriod, ok := data.GetOk("deletion_grace_period"); ok {
+		config.DeletionGracePeriod = int64(gracePeriod.(int))
+	}
+	if timeWindow, ok := data.GetOk("auth_time_window"); ok {
+		if timeWindow.(int) <= 0 {
+			return nil, fmt.Errorf("auth_time_window must be positive")
+		}
+		config.AuthTimeWindow = int64(timeWindow.(int))
+	}
+	if maxTTL, ok := data.GetOk("jwt_max_ttl"); ok {
+		if maxTTL.(int) <= 0 {
+			return nil, fmt.Errorf("jwt_max_ttl must be positive")
+		}
+		config.JWTMaxTTL = int64(maxTTL.(int))
+	}
+	if audiences, ok := data.GetOk("jwt_audiences"); ok {
+		config.JWTAudiences = audiences.([]string)
+	}
+	if maxTTL, ok := data.GetOk("certificate_max_ttl"); ok {
+		if maxTTL.(int) <= 0 {
+			return nil, fmt.Errorf("certificate_max_ttl must be positive")
+		}
+		config.CertificateMaxTTL = int64(maxTTL.(int))
+	}
+
+	entry, err := logical.StorageEntryJSON(configPath, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the plugin config", "error", err)
+		return nil, err
+	}
+	return b.readConfig(ctx, req, data)
+}
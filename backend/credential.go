@@ -0,0 +1,226 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// DIDMethodPrefix is the prefix of the DID of an ICON account, followed by its address
+	DIDMethodPrefix = "did:icon:"
+	// CredentialProofType is the proof type of the credentials signed by the plugin,
+	// a detached ES256K JWS over the JCS (RFC 8785) form of the proof options and
+	// of the credential. The credential is not processed as JSON-LD.
+	CredentialProofType = "JcsEcdsaSecp256k1Signature2019"
+	// didKeyFragment identifies the account key in the DID document
+	didKeyFragment = "#key-1"
+)
+
+// detachedJWSHeader is the header of the unencoded, detached payload JWS of a proof, RFC 7797.
+var detachedJWSHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256K","b64":false,"crit":["b64"]}`))
+
+// DID returns the did:icon DID of the address.
+func DID(address string) string {
+	return DIDMethodPrefix + address
+}
+
+// DIDDocument returns the DID document of the account key.
+func DIDDocument(publicKey *PublicKey) (map[string]interface{}, error) {
+	did := DID(publicKey.Address())
+	keyID := did + didKeyFragment
+	jwk, err := JWK(publicKey, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"@context": []string{
+			"https://www.w3.org/ns/did/v1",
+			"https://w3id.org/security/suites/secp256k1-2019/v1",
+		},
+		"id": did,
+		"verificationMethod": []map[string]interface{}{
+			{
+				"id":           keyID,
+				"type":         "EcdsaSecp256k1VerificationKey2019",
+				"controller":   did,
+				"publicKeyJwk": jwk,
+			},
+		},
+		"authentication":  []string{keyID},
+		"assertionMethod": []string{keyID},
+	}, nil
+}
+
+// credentialSigningInput returns the JWS signing input of a proof, the hash of
+// the canonical proof options followed by the hash of the canonical credential.
+func credentialSigningInput(credential, proofOptions map[string]interface{}) ([]byte, error) {
+	canonicalOptions, err := canonicalJSON(proofOptions)
+	if err != nil {
+		return nil, err
+	}
+	canonicalCredential, err := canonicalJSON(credential)
+	if err != nil {
+		return nil, err
+	}
+	optionsHash := sha256.Sum256(canonicalOptions)
+	credentialHash := sha256.Sum256(canonicalCredential)
+	input := []byte(detachedJWSHeader + ".")
+	input = append(input, optionsHash[:]...)
+	return append(input, credentialHash[:]...), nil
+}
+
+// SignCredential returns a copy of the credential with a
+// JcsEcdsaSecp256k1Signature2019 proof made by the private key.
+func SignCredential(credential map[string]interface{}, proofPurpose string, created time.Time, privateKey *PrivateKey) (map[string]interface{}, error) {
+	if _, ok := credential["proof"]; ok {
+		return nil, errors.New("credential already has a proof")
+	}
+	proof := map[string]interface{}{
+		"type":               CredentialProofType,
+		"created":            created.UTC().Format(time.RFC3339),
+		"verificationMethod": DID(privateKey.PublicKey().Address()) + didKeyFragment,
+		"proofPurpose":       proofPurpose,
+	}
+	input, err := credentialSigningInput(credential, proof)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(input)
+	signature, err := NewSignature(hash[:], privateKey)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := signature.SerializeRS()
+	if err != nil {
+		return nil, err
+	}
+	proof["jws"] = detachedJWSHeader + ".." + base64.RawURLEncoding.EncodeToString(rs)
+
+	signed := make(map[string]interface{}, len(credential)+1)
+	for key, value := range credential {
+		signed[key] = value
+	}
+	signed["proof"] = proof
+	return signed, nil
+}
+
+// VerifyCredential checks the proof of a credential signed by the public key.
+func VerifyCredential(signed map[string]interface{}, publicKey *PublicKey) error {
+	proof, ok := signed["proof"].(map[string]interface{})
+	if !ok {
+		return errors.New("credential has no proof")
+	}
+	if proof["type"] != CredentialProofType {
+		return fmt.Errorf("unsupported proof type - %v", proof["type"])
+	}
+	jws, _ := proof["jws"].(string)
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[0] != detachedJWSHeader || parts[1] != "" {
+		return errors.New("malformed proof jws")
+	}
+	rs, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(rs) != SignatureLenRaw {
+		return errors.New("malformed proof signature")
+	}
+
+	credential := make(map[string]interface{}, len(signed))
+	for key, value := range signed {
+		if key != "proof" {
+			credential[key] = value
+		}
+	}
+	options := make(map[string]interface{}, len(proof))
+	for key, value := range proof {
+		if key != "jws" {
+			options[key] = value
+		}
+	}
+	input, err := credentialSigningInput(credential, options)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(input)
+	signature, _ := ParseSignature(rs)
+	if !signature.Verify(hash[:], publicKey) {
+		return errors.New("invalid credential proof")
+	}
+	return nil
+}
+
+// credentialIssuer returns the issuer ID of a credential, which is a string or an object with an id.
+func credentialIssuer(credential map[string]interface{}) (string, bool) {
+	switch issuer := credential["issuer"].(type) {
+	case string:
+		return issuer, true
+	case map[string]interface{}:
+		id, _ := issuer["id"].(string)
+		return id, true
+	default:
+		return "", false
+	}
+}
+
+func (b *backend) signCredential(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	credential := data.Get("credential").(map[string]interface{})
+	if len(credential) == 0 {
+		return nil, errors.New("credential is required")
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+	did := DID(account.Address)
+	if issuer, ok := credentialIssuer(credential); !ok {
+		credential["issuer"] = did
+	} else if issuer != did {
+		return nil, fmt.Errorf("credential issuer %s is not %s", issuer, did)
+	}
+
+	signed, err := SignCredential(credential, data.Get("proof_purpose").(string), time.Now(), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[VC][OK] Signed a credential", "address", account.Address)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"did":        did,
+			"credential": signed,
+		},
+	}, nil
+}
+
+func (b *backend) readDIDDocument(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	_, publicKey, err := b.retrievePublicKey(ctx, req, data.Get("name").(string), "DID")
+	if err != nil {
+		return nil, err
+	}
+	document, err := DIDDocument(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: document,
+	}, nil
+}
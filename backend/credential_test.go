@@ -0,0 +1,163 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+// The vectors are from RFC 8785, sections 3.2.2 and 3.2.3 and appendix B.
+func TestCanonicalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(`{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`))
+	decoder.UseNumber()
+	assert.Nil(decoder.Decode(&value))
+	canonical, err := canonicalJSON(value)
+	assert.Nil(err)
+	assert.Equal(`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(canonical))
+
+	canonical, err = canonicalJSON(map[string]interface{}{
+		"\u20ac":     "Euro Sign",
+		"\r":         "Carriage Return",
+		"\ufb33":     "Hebrew Letter Dalet With Dagesh",
+		"1":          "One",
+		"\U0001f600": "Emoji: Grinning Face",
+		"\u0080":     "Control",
+		"\u00f6":     "Latin Small Letter O With Diaeresis",
+	})
+	assert.Nil(err)
+	assert.Equal("{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\","+
+		"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\","+
+		"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(canonical))
+
+	for bits, expected := range map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	} {
+		number, err := canonicalNumber(math.Float64frombits(bits))
+		assert.Nil(err)
+		assert.Equal(expected, number, "%016x", bits)
+	}
+	_, err = canonicalNumber(math.Inf(1))
+	assert.NotNil(err)
+}
+
+func TestSignCredential(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+	did := "did:icon:" + address
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address+"/did")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, did, resp.Data["id"])
+	assert.Equal(t, []string{did + "#key-1"}, resp.Data["assertionMethod"])
+	method := resp.Data["verificationMethod"].([]map[string]interface{})[0]
+	assert.Equal(t, "EcdsaSecp256k1VerificationKey2019", method["type"])
+	jwk := method["publicKeyJwk"].(map[string]interface{})
+	x, _ := base64.RawURLEncoding.DecodeString(jwk["x"].(string))
+	y, _ := base64.RawURLEncoding.DecodeString(jwk["y"].(string))
+	publicKey, err := ParsePublicKey(append(append([]byte{0x04}, x...), y...))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, publicKey.Address())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/credential")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"credential": map[string]interface{}{
+			"@context":     []interface{}{"https://www.w3.org/2018/credentials/v1"},
+			"type":         []interface{}{"VerifiableCredential"},
+			"issuanceDate": "2023-01-01T00:00:00Z",
+			"credentialSubject": map[string]interface{}{
+				"id":    "did:icon:hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+				"level": 3,
+			},
+		},
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	signed := resp.Data["credential"].(map[string]interface{})
+	assert.Equal(t, did, signed["issuer"])
+	proof := signed["proof"].(map[string]interface{})
+	assert.Equal(t, "JcsEcdsaSecp256k1Signature2019", proof["type"])
+	assert.Equal(t, did+"#key-1", proof["verificationMethod"])
+	assert.Equal(t, "assertionMethod", proof["proofPurpose"])
+
+	// the proof survives the JSON round trip of a holder
+	raw, _ := json.Marshal(signed)
+	var received map[string]interface{}
+	_ = json.Unmarshal(raw, &received)
+	assert.Nil(t, VerifyCredential(received, publicKey))
+
+	received["credentialSubject"].(map[string]interface{})["level"] = 4
+	assert.EqualError(t, VerifyCredential(received, publicKey), "invalid credential proof")
+
+	req.Data = map[string]interface{}{
+		"credential": map[string]interface{}{
+			"issuer": map[string]interface{}{"id": "did:icon:hxc1d72af5b89ea6594a7e17ca7a804d52d2474462"},
+		},
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.EqualError(t, err, "credential issuer did:icon:hxc1d72af5b89ea6594a7e17ca7a804d52d2474462 is not "+did)
+}
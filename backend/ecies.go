@@ -0,0 +1,252 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// eciesNonceLen is the AES-GCM nonce length of the ECIES ciphertexts
+const eciesNonceLen = 16
+
+// keyAgreementPolicies are the checks applied before using the key to decrypt
+// or derive a shared secret, which is only allowed for the accounts that enabled it.
+var keyAgreementPolicies = append(append([]signingPolicy{}, transactionSigningPolicies...), permissionPolicy("key_agreement"))
+
+// eciesSharedPoint returns the uncompressed ECDH point of the private key and
+// the public key. The account key is multiplied by points the caller chooses,
+// as often as it asks, so the multiplication is constant time.
+func eciesSharedPoint(privateKey *PrivateKey, publicKey *PublicKey) ([]byte, error) {
+	pub, err := secp256k1.ParsePubKey(publicKey.bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv := secp256k1.PrivKeyFromBytes(privateKey.bytes)
+	defer priv.Zero()
+
+	shared, err := scalarMultConst(&priv.Key, pub)
+	if err != nil {
+		return nil, err
+	}
+	return shared.SerializeUncompressed(), nil
+}
+
+// eciesKey derives the AES-256 key from the ephemeral public key and the shared point.
+func eciesKey(ephemeral, shared []byte) ([]byte, error) {
+	return hkdfSHA256(append(append([]byte{}, ephemeral...), shared...), nil, "")
+}
+
+// EncryptECIES encrypts the plaintext to the public key. The ciphertext is the
+// uncompressed ephemeral public key, the nonce, the tag and the AES-256-GCM
+// ciphertext, and the key is HKDF-SHA256 of the ephemeral public key and the
+// uncompressed shared point, as in eciesjs.
+func EncryptECIES(publicKey *PublicKey, plaintext []byte) ([]byte, error) {
+	ephemeral, _ := GenerateKey()
+	nonce := make([]byte, eciesNonceLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return encryptECIES(publicKey, plaintext, ephemeral, nonce)
+}
+
+// encryptECIES encrypts with the given ephemeral key and nonce, which must
+// never be reused.
+func encryptECIES(publicKey *PublicKey, plaintext []byte, ephemeral *PrivateKey, nonce []byte) ([]byte, error) {
+	ephemeralPublicKey := ephemeral.PublicKey()
+	shared, err := eciesSharedPoint(ephemeral, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralBytes := ephemeralPublicKey.SerializeUncompressed()
+	key, err := eciesKey(ephemeralBytes, shared)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := eciesAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nil, nonce, plaintext, nil)
+	ciphertext, tag := sealed[:len(plaintext)], sealed[len(plaintext):]
+
+	out := make([]byte, 0, len(ephemeralBytes)+len(nonce)+len(sealed))
+	out = append(out, ephemeralBytes...)
+	out = append(out, nonce...)
+	out = append(out, tag...)
+	return append(out, ciphertext...), nil
+}
+
+// DecryptECIES decrypts a ciphertext of EncryptECIES with the private key.
+func DecryptECIES(privateKey *PrivateKey, data []byte) ([]byte, error) {
+	headerLen := PublicKeyLenUncompressed + eciesNonceLen + 16
+	if len(data) < headerLen {
+		return nil, errors.New("ciphertext is too short")
+	}
+	ephemeralBytes := data[:PublicKeyLenUncompressed]
+	nonce := data[PublicKeyLenUncompressed : PublicKeyLenUncompressed+eciesNonceLen]
+	tag := data[PublicKeyLenUncompressed+eciesNonceLen : headerLen]
+	ciphertext := data[headerLen:]
+
+	if ephemeralBytes[0] != publicKeyUncompressed {
+		return nil, errors.New("invalid ephemeral public key")
+	}
+	ephemeralPublicKey, err := ParsePublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := eciesSharedPoint(privateKey, ephemeralPublicKey)
+	if err != nil {
+		return nil, errors.New("invalid ephemeral public key")
+	}
+	key, err := eciesKey(ephemeralBytes, shared)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := eciesAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, append(append([]byte{}, ciphertext...), tag...), nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt the ciphertext")
+	}
+	return plaintext, nil
+}
+
+func eciesAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, eciesNonceLen)
+}
+
+// parsePublicKeyHex parses a 0x-prefixed or plain hex public key, compressed or uncompressed.
+func parsePublicKeyHex(s string) (*PublicKey, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key - %v", err)
+	}
+	if len(raw) != PublicKeyLenCompressed && len(raw) != PublicKeyLenUncompressed {
+		return nil, fmt.Errorf("invalid public key length %d", len(raw))
+	}
+	publicKey, err := ParsePublicKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := secp256k1.ParsePubKey(publicKey.bytes); err != nil {
+		return nil, fmt.Errorf("invalid public key - %v", err)
+	}
+	return publicKey, nil
+}
+
+func (b *backend) encryptECIES(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	plaintext, err := base64.StdEncoding.DecodeString(data.Get("plaintext").(string))
+	if err != nil {
+		return nil, fmt.Errorf("plaintext must be base64 - %v", err)
+	}
+	var publicKey *PublicKey
+	switch name, key := data.Get("name").(string), data.Get("public_key").(string); {
+	case name != "" && key == "":
+		if _, publicKey, err = b.retrievePublicKey(ctx, req, name, "ECIES"); err != nil {
+			return nil, err
+		}
+	case name == "" && key != "":
+		if publicKey, err = parsePublicKeyHex(key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("exactly one of name or public_key is required")
+	}
+	ciphertext, err := EncryptECIES(publicKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    publicKey.Address(),
+			"ciphertext": base64.StdEncoding.EncodeToString(ciphertext),
+		},
+	}, nil
+}
+
+func (b *backend) decryptECIES(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(data.Get("ciphertext").(string))
+	if err != nil {
+		return nil, fmt.Errorf("ciphertext must be base64 - %v", err)
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), keyAgreementPolicies)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := DecryptECIES(privateKey, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[ECIES][OK] Decrypted a ciphertext", "address", account.Address)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":   account.Address,
+			"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+		},
+	}, nil
+}
+
+func (b *backend) deriveECDH(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	peer, err := parsePublicKeyHex(data.Get("public_key").(string))
+	if err != nil {
+		return nil, err
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), keyAgreementPolicies)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := eciesSharedPoint(privateKey, peer)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "ecdh", account.Address, map[string]interface{}{
+		"peer": peer.String(),
+	}); err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address": account.Address,
+			// the x coordinate of the shared point, as in SEC 1 and RFC 5903
+			"shared_secret": "0x" + hex.EncodeToString(shared[1:33]),
+		},
+	}, nil
+}
+
+func (b *backend) enableKeyAgreement(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountPermission(ctx, req, data.Get("name").(string), "key_agreement", true)
+}
+
+func (b *backend) disableKeyAgreement(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountPermission(ctx, req, data.Get("name").(string), "key_agreement", false)
+}
@@ -0,0 +1,181 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECIES(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	call := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Storage = storage
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	plaintext := []byte("validator key share")
+	resp, err := call("encrypt", map[string]interface{}{
+		"name":      address,
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, resp.Data["address"])
+	ciphertext := resp.Data["ciphertext"].(string)
+
+	// disabled by default
+	_, err = call("accounts/"+address+"/decrypt", map[string]interface{}{"ciphertext": ciphertext})
+	assert.EqualError(t, err, "Signing account "+address+" does not allow key_agreement")
+
+	if _, err := call("accounts/"+address+"/key_agreement/enable", nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err = call("accounts/"+address+"/decrypt", map[string]interface{}{"ciphertext": ciphertext})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	decrypted, _ := base64.StdEncoding.DecodeString(resp.Data["plaintext"].(string))
+	assert.Equal(t, plaintext, decrypted)
+
+	// a flipped bit fails the authentication
+	raw, _ := base64.StdEncoding.DecodeString(ciphertext)
+	raw[len(raw)-1] ^= 1
+	_, err = call("accounts/"+address+"/decrypt", map[string]interface{}{
+		"ciphertext": base64.StdEncoding.EncodeToString(raw),
+	})
+	assert.EqualError(t, err, "failed to decrypt the ciphertext")
+
+	// both sides derive the same secret
+	peerPrivateKey, peerPublicKey := GenerateKey()
+	resp, err = call("accounts/"+address+"/ecdh", map[string]interface{}{"public_key": peerPublicKey.String()})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, accountPublicKey, err := b.(*backend).retrievePublicKey(context.Background(), req, address, "ECDH")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	shared, err := eciesSharedPoint(peerPrivateKey, accountPublicKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "0x"+hex.EncodeToString(shared[1:33]), resp.Data["shared_secret"])
+
+	if _, err := call("accounts/"+address+"/key_agreement/disable", nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = call("accounts/"+address+"/ecdh", map[string]interface{}{"public_key": peerPublicKey.String()})
+	assert.EqualError(t, err, "Signing account "+address+" does not allow key_agreement")
+
+	// encrypting to a bare public key needs no account
+	resp, err = call("encrypt", map[string]interface{}{
+		"public_key": peerPublicKey.String(),
+		"plaintext":  base64.StdEncoding.EncodeToString(plaintext),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	raw, _ = base64.StdEncoding.DecodeString(resp.Data["ciphertext"].(string))
+	decrypted, err = DecryptECIES(peerPrivateKey, raw)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = call("encrypt", map[string]interface{}{"plaintext": ""})
+	assert.EqualError(t, err, "exactly one of name or public_key is required")
+}
+
+func TestECIESVector(t *testing.T) {
+	// the eciesjs default format, produced independently with the ECDH, HKDF
+	// and AES-256-GCM of Node's crypto module
+	receiver, _ := ParsePrivateKeyFromString("d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32")
+	ephemeral, _ := ParsePrivateKeyFromString("3f1a2b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708")
+	nonce, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	vector, _ := hex.DecodeString("041ac59e47eb1535c707fcdd27edef85ec7147bd831534e7b9e94635bae62dfad8" +
+		"b7f41def8d666c742741bb292857767984e31d8095df6b6c15e0cae60a6be29a" +
+		"000102030405060708090a0b0c0d0e0f" +
+		"18844de85f483736b7625a21dc439c01" +
+		"006f8bac287d6559c478")
+
+	plaintext, err := DecryptECIES(receiver, vector)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello ICON", string(plaintext))
+
+	ciphertext, err := encryptECIES(receiver.PublicKey(), []byte("hello ICON"), ephemeral, nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, vector, ciphertext)
+}
+
+func TestScalarMultConst(t *testing.T) {
+	var one, minusOne, two secp256k1.ModNScalar
+	one.SetInt(1)
+	minusOne.NegateVal(&one)
+	two.SetInt(2)
+	scalars := []*secp256k1.ModNScalar{&one, &minusOne, &two}
+	for i := 0; i < 16; i++ {
+		privateKey, _ := GenerateKey()
+		var k secp256k1.ModNScalar
+		k.SetByteSlice(privateKey.bytes)
+		scalars = append(scalars, &k)
+	}
+	for _, k := range scalars {
+		seed := k.Bytes()
+		for _, point := range []*secp256k1.PublicKey{secp256k1Generator, secp256k1.PrivKeyFromBytes(SHA3Sum256(seed[:])).PubKey()} {
+			var jacobian, expected secp256k1.JacobianPoint
+			point.AsJacobian(&jacobian)
+			secp256k1.ScalarMultNonConst(k, &jacobian, &expected)
+			expected.ToAffine()
+
+			product, err := scalarMultConst(k, point)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			assert.Equal(t, secp256k1.NewPublicKey(&expected.X, &expected.Y).SerializeUncompressed(), product.SerializeUncompressed())
+		}
+	}
+}
+
+func TestInverseModNConst(t *testing.T) {
+	var minusOne secp256k1.ModNScalar
+	minusOne.SetInt(1).Negate()
+	scalars := []*secp256k1.ModNScalar{new(secp256k1.ModNScalar).SetInt(1), new(secp256k1.ModNScalar).SetInt(2), &minusOne}
+	for i := 0; i < 16; i++ {
+		privateKey, _ := GenerateKey()
+		var k secp256k1.ModNScalar
+		k.SetByteSlice(privateKey.bytes)
+		scalars = append(scalars, &k)
+	}
+	for _, k := range scalars {
+		expected := new(secp256k1.ModNScalar).InverseValNonConst(k)
+		assert.True(t, expected.Equals(inverseModNConst(k)))
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// projectivePoint is a point of secp256k1 in homogeneous projective
+// coordinates, where (X:Y:Z) is the affine point (X/Z, Y/Z) and (0:1:0) is
+// the point at infinity. Every coordinate is kept normalized.
+type projectivePoint struct {
+	X, Y, Z secp256k1.FieldVal
+}
+
+func fieldAdd(r, a, b *secp256k1.FieldVal) {
+	r.Add2(a, b).Normalize()
+}
+
+func fieldSub(r, a, b *secp256k1.FieldVal) {
+	var negated secp256k1.FieldVal
+	negated.NegateVal(b, 1)
+	r.Add2(a, &negated).Normalize()
+}
+
+// fieldCondSwap swaps a and b if bit is 1 and leaves them if it is 0, with
+// the same operations in both cases.
+func fieldCondSwap(a, b *secp256k1.FieldVal, bit uint32) {
+	var d secp256k1.FieldVal
+	fieldSub(&d, b, a)
+	d.MulInt(uint8(bit))
+	a.Add(&d).Normalize()
+	b.Add(d.Negate(1)).Normalize()
+}
+
+func (p *projectivePoint) condSwap(q *projectivePoint, bit uint32) {
+	fieldCondSwap(&p.X, &q.X, bit)
+	fieldCondSwap(&p.Y, &q.Y, bit)
+	fieldCondSwap(&p.Z, &q.Z, bit)
+}
+
+// addComplete sets r to p + q with the complete addition formula for a = 0
+// curves of Renes, Costello and Batina (2016, algorithm 7). It has no special
+// case for doubling or the point at infinity, so its operations don't depend
+// on the points.
+func (r *projectivePoint) addComplete(p, q *projectivePoint) {
+	const b3 = 3 * 7
+	var t0, t1, t2, t3, t4, x3, y3, z3 secp256k1.FieldVal
+	t0.Mul2(&p.X, &q.X).Normalize()
+	t1.Mul2(&p.Y, &q.Y).Normalize()
+	t2.Mul2(&p.Z, &q.Z).Normalize()
+	fieldAdd(&t3, &p.X, &p.Y)
+	fieldAdd(&t4, &q.X, &q.Y)
+	t3.Mul(&t4).Normalize()
+	fieldAdd(&t4, &t0, &t1)
+	fieldSub(&t3, &t3, &t4)
+	fieldAdd(&t4, &p.Y, &p.Z)
+	fieldAdd(&x3, &q.Y, &q.Z)
+	t4.Mul(&x3).Normalize()
+	fieldAdd(&x3, &t1, &t2)
+	fieldSub(&t4, &t4, &x3)
+	fieldAdd(&x3, &p.X, &p.Z)
+	fieldAdd(&y3, &q.X, &q.Z)
+	x3.Mul(&y3).Normalize()
+	fieldAdd(&y3, &t0, &t2)
+	fieldSub(&y3, &x3, &y3)
+	fieldAdd(&x3, &t0, &t0)
+	fieldAdd(&t0, &x3, &t0)
+	t2.MulInt(b3).Normalize()
+	fieldAdd(&z3, &t1, &t2)
+	fieldSub(&t1, &t1, &t2)
+	y3.MulInt(b3).Normalize()
+	x3.Mul2(&t4, &y3).Normalize()
+	t2.Mul2(&t3, &t1).Normalize()
+	fieldSub(&x3, &t2, &x3)
+	y3.Mul(&t0).Normalize()
+	t1.Mul(&z3).Normalize()
+	fieldAdd(&y3, &t1, &y3)
+	t0.Mul(&t3).Normalize()
+	z3.Mul(&t4).Normalize()
+	fieldAdd(&z3, &z3, &t0)
+	r.X, r.Y, r.Z = x3, y3, z3
+}
+
+// scalarMultConst returns the affine point k*P of a secret scalar and a
+// public point with a Montgomery ladder over complete additions. Unlike
+// secp256k1.ScalarMultNonConst, the sequence of field operations doesn't
+// depend on the scalar or the point, which matters when the scalar is a
+// long-term key and the point is chosen by the caller.
+func scalarMultConst(k *secp256k1.ModNScalar, point *secp256k1.PublicKey) (*secp256k1.PublicKey, error) {
+	var r0, r1 projectivePoint
+	r0.Y.SetInt(1)
+	var affine secp256k1.JacobianPoint
+	point.AsJacobian(&affine)
+	r1.X, r1.Y = affine.X, affine.Y
+	r1.X.Normalize()
+	r1.Y.Normalize()
+	r1.Z.SetInt(1)
+
+	scalar := k.Bytes()
+	defer zeroArray(scalar[:])
+	for i := 255; i >= 0; i-- {
+		bit := uint32(scalar[31-i/8]>>(uint(i)%8)) & 1
+		r0.condSwap(&r1, bit)
+		r1.addComplete(&r0, &r1)
+		r0.addComplete(&r0, &r0)
+		r0.condSwap(&r1, bit)
+	}
+
+	if r0.Z.IsZero() {
+		return nil, errors.New("scalar multiplication is the point at infinity")
+	}
+	zInv := r0.Z
+	zInv.Inverse()
+	r0.X.Mul(&zInv).Normalize()
+	r0.Y.Mul(&zInv).Normalize()
+	return secp256k1.NewPublicKey(&r0.X, &r0.Y), nil
+}
+
+var (
+	// secp256k1Generator is the base point G, the public key of 1
+	secp256k1Generator = secp256k1.NewPrivateKey(new(secp256k1.ModNScalar).SetInt(1)).PubKey()
+	// orderMinusTwo is n-2, the exponent of the inverse modulo the group
+	// order by Fermat's little theorem
+	orderMinusTwo = new(secp256k1.ModNScalar).SetInt(2).Negate().Bytes()
+)
+
+// inverseModNConst returns k^-1 mod n as k^(n-2). Unlike
+// ModNScalar.InverseNonConst, the multiplications only follow the bits of the
+// public exponent, so they don't depend on k.
+func inverseModNConst(k *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	result := new(secp256k1.ModNScalar).SetInt(1)
+	for _, b := range orderMinusTwo {
+		for i := 7; i >= 0; i-- {
+			result.Square()
+			if (b>>uint(i))&1 == 1 {
+				result.Mul(k)
+			}
+		}
+	}
+	return result
+}
+
+func zeroArray(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// ICONCoinType is the SLIP-44 coin type registered for ICON
+	ICONCoinType = 74
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+)
+
+var (
+	masterKeySeed = []byte("Bitcoin seed")
+	// secp256k1N is the order of the secp256k1 curve
+	secp256k1N, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+)
+
+// HDWallet is a BIP-39 seed stored by the plugin, from which ICON accounts are derived
+type HDWallet struct {
+	Name      string `json:"name"`
+	Seed      string `json:"seed"`
+	NextIndex uint32 `json:"next_index"`
+	// Exported is set once the seed exists outside the plugin, as a handed out
+	// or imported mnemonic or a backup, after which only exportable accounts
+	// can be derived from it
+	Exported  bool  `json:"exported,omitempty"`
+	CreatedAt int64 `json:"created_at"`
+}
+
+// ExtendedKey is a BIP-32 extended private key
+type ExtendedKey struct {
+	key       []byte // 32-byte private key
+	chainCode []byte // 32-byte chain code
+}
+
+// NewMasterKey derives the BIP-32 master key from a BIP-39 seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("seed length must be between 128 and 512 bits")
+	}
+	mac := hmac.New(sha512.New, masterKeySeed)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	if _, err := ParsePrivateKey(sum[:32]); err != nil {
+		return nil, fmt.Errorf("unusable master key: %v", err)
+	}
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the child extended key at the given index. Indexes starting
+// from HardenedKeyStart produce hardened children.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedKeyStart {
+		data = append(data, 0x00)
+		data = append(data, k.key...)
+	} else {
+		data = append(data, secp256k1.PrivKeyFromBytes(k.key).PubKey().SerializeCompressed()...)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(secp256k1N) >= 0 {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	il.Add(il, new(big.Int).SetBytes(k.key))
+	il.Mod(il, secp256k1N)
+	if il.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	child := make([]byte, PrivateKeyLen)
+	il.FillBytes(child)
+	return &ExtendedKey{key: child, chainCode: sum[32:]}, nil
+}
+
+// Derive walks the given derivation path from this key.
+func (k *ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey returns the private key of the extended key.
+func (k *ExtendedKey) PrivateKey() (*PrivateKey, error) {
+	return ParsePrivateKey(k.key)
+}
+
+// ParseDerivationPath parses a path such as m/44'/74'/0'/0/0.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	elements := strings.Split(strings.TrimSpace(path), "/")
+	if len(elements) == 0 || elements[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with 'm' - %s", path)
+	}
+	var indexes []uint32
+	for _, element := range elements[1:] {
+		hardened := strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h")
+		if hardened {
+			element = element[:len(element)-1]
+		}
+		index, err := strconv.ParseUint(element, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path element %q", element)
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// DerivePrivateKey derives the private key of the path from the seed.
+func DerivePrivateKey(seed []byte, path string) (*PrivateKey, error) {
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	child, err := master.Derive(indexes)
+	if err != nil {
+		return nil, err
+	}
+	return child.PrivateKey()
+}
+
+// ICONDerivationPath returns the path m/44'/74'/account'/0/index.
+func ICONDerivationPath(account, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0/%d", ICONCoinType, account, index)
+}
+
+func (b *backend) listHDWallets(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	vals, err := req.Storage.List(ctx, "hd/")
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of HD wallets", "error", err)
+		return nil, err
+	}
+	return logical.ListResponse(vals), nil
+}
+
+func (b *backend) createHDWallet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	mnemonic := strings.Join(strings.Fields(data.Get("mnemonic").(string)), " ")
+	passphrase := data.Get("passphrase").(string)
+	strength := data.Get("strength").(int)
+
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	lock := locksutil.LockForKey(b.hdWalletLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	wallet, err := b.retrieveHDWallet(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if wallet != nil {
+		return nil, fmt.Errorf("HD wallet already exists - %s", name)
+	}
+
+	generated := mnemonic == ""
+	if generated {
+		// The generated mnemonic must never land in a plain response body.
+		if req.WrapInfo == nil || req.WrapInfo.TTL == 0 {
+			return nil, fmt.Errorf("[CREATE][FAIL] Generating a mnemonic requires response wrapping")
+		}
+		if strength != 128 && strength != 256 {
+			return nil, fmt.Errorf("strength must be 128 or 256 bits - input: %d", strength)
+		}
+		entropy, err := bip39.NewEntropy(strength)
+		if err != nil {
+			return nil, err
+		}
+		if mnemonic, err = bip39.NewMnemonic(entropy); err != nil {
+			return nil, err
+		}
+	} else if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid BIP-39 mnemonic")
+	}
+
+	seed := bip39.NewSeed(mnemonic, passphrase)
+	if _, err := NewMasterKey(seed); err != nil {
+		return nil, err
+	}
+
+	wallet = &HDWallet{
+		Name:      name,
+		Seed:      hex.EncodeToString(seed),
+		Exported:  true,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := b.storeHDWallet(ctx, req, wallet); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[OK] Save the new HD wallet", "name", name, "generated", generated)
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"name": name,
+		},
+	}
+	// The mnemonic is only handed out once, when it was generated by the plugin.
+	if generated {
+		resp.Data["mnemonic"] = mnemonic
+	}
+	return resp, nil
+}
+
+func (b *backend) readHDWallet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	wallet, err := b.retrieveHDWallet(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("[READ][FAIL] HD wallet does not exist - %s", name)
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":       wallet.Name,
+			"next_index": wallet.NextIndex,
+			"exported":   wallet.Exported,
+			"created_at": wallet.CreatedAt,
+		},
+	}, nil
+}
+
+func (b *backend) deleteHDWallet(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if err := req.Storage.Delete(ctx, fmt.Sprintf("hd/%s", name)); err != nil {
+		b.Logger().Error("[DELETE][FAIL] Failed to delete the HD wallet from storage", "name", name, "error", err)
+		return nil, err
+	}
+	b.Logger().Info("[DELETE][OK] Deleted successfully", "name", name)
+	return nil, nil
+}
+
+func (b *backend) deriveHDAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	aliasName := data.Get("alias_name").(string)
+	accountIndex := data.Get("account").(int)
+	exportable := data.Get("exportable").(bool)
+
+	// next_index is read and advanced under the lock, so that concurrent
+	// derivations do not get the same index
+	lock := locksutil.LockForKey(b.hdWalletLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	wallet, err := b.retrieveHDWallet(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("HD wallet does not exist - %s", name)
+	}
+	if wallet.Exported && !exportable {
+		return nil, fmt.Errorf("HD wallet %s exists outside the plugin, derived accounts must be exportable", name)
+	}
+
+	index := wallet.NextIndex
+	if rawIndex, ok := data.GetOk("index"); ok {
+		index = uint32(rawIndex.(int))
+	}
+	if accountIndex < 0 || uint32(accountIndex) >= HardenedKeyStart || index >= HardenedKeyStart {
+		return nil, fmt.Errorf("account and index must be between 0 and %d", HardenedKeyStart-1)
+	}
+
+	seed, err := hex.DecodeString(wallet.Seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the stored seed: %v", err)
+	}
+	derivationPath := ICONDerivationPath(uint32(accountIndex), index)
+	privateKey, err := DerivePrivateKey(seed, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := privateKey.PublicKey()
+	stored, err := b.checkAccountConflict(ctx, req, publicKey.Address(), aliasName, data.Get("overwrite").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Address:        publicKey.Address(),
+		PrivateKey:     privateKey.String(),
+		PublicKey:      publicKey.String(),
+		AliasName:      aliasName,
+		HDWallet:       wallet.Name,
+		DerivationPath: derivationPath,
+		Exportable:     exportable,
+	}
+	account.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+
+	if index >= wallet.NextIndex {
+		wallet.NextIndex = index + 1
+		if err := b.storeHDWallet(ctx, req, wallet); err != nil {
+			return nil, err
+		}
+	}
+	b.Logger().Info("[OK] Derived account", "wallet", wallet.Name, "address", account.Address, "derivation_path", derivationPath)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":         account.Address,
+			"alias_name":      account.AliasName,
+			"derivation_path": derivationPath,
+		},
+	}, nil
+}
+
+func (b *backend) retrieveHDWallet(ctx context.Context, req *logical.Request, name string) (*HDWallet, error) {
+	entry, err := req.Storage.Get(ctx, fmt.Sprintf("hd/%s", name))
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the HD wallet", "name", name, "error", err)
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	var wallet HDWallet
+	if err := entry.DecodeJSON(&wallet); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+func (b *backend) storeHDWallet(ctx context.Context, req *logical.Request, wallet *HDWallet) error {
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf("hd/%s", wallet.Name), wallet)
+	if err != nil {
+		return err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the HD wallet to storage", "name", wallet.Name, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestExtendedKeyDerive(t *testing.T) {
+	// BIP-32 test vector 1
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(master.key))
+
+	vectors := map[string]string{
+		"m/0'":      "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		"m/0'/1":    "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		"m/0'/1/2'": "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca",
+	}
+	for path, expected := range vectors {
+		indexes, err := ParseDerivationPath(path)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		child, err := master.Derive(indexes)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assert.Equal(t, expected, hex.EncodeToString(child.key), path)
+	}
+}
+
+func TestParseDerivationPathFailure(t *testing.T) {
+	for _, path := range []string{"", "44'/74'", "m/abc", "m/2147483648"} {
+		_, err := ParseDerivationPath(path)
+		assert.NotNil(t, err, path)
+	}
+}
+
+func TestHDWallet(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "hd")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name": "treasury",
+	}
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal("[CREATE][FAIL] Generating a mnemonic requires response wrapping", err.Error())
+
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	mnemonic := resp.Data["mnemonic"].(string)
+	assert.True(bip39.IsMnemonicValid(mnemonic))
+
+	// importing under the same name is refused
+	req.Data["mnemonic"] = mnemonic
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("HD wallet already exists - treasury", err.Error())
+
+	var addresses []string
+	for i := 0; i < 2; i++ {
+		req = logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/derive")
+		req.Storage = storage
+		req.Data = map[string]interface{}{"exportable": true}
+		resp, err = b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assert.Equal(ICONDerivationPath(0, uint32(i)), resp.Data["derivation_path"])
+		addresses = append(addresses, resp.Data["address"].(string))
+	}
+	assert.NotEqual(addresses[0], addresses[1])
+
+	// restoring the mnemonic into another wallet derives the same accounts
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":     "restored",
+		"mnemonic": mnemonic,
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(resp.Data["mnemonic"])
+
+	// the imported mnemonic exists outside the plugin
+	req = logical.TestRequest(t, logical.UpdateOperation, "hd/restored/derive")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"index": 1,
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("HD wallet restored exists outside the plugin, derived accounts must be exportable", err.Error())
+
+	req.Data["exportable"] = true
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("account already exists - "+addresses[1], err.Error())
+
+	req.Data["overwrite"] = true
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(addresses[1], resp.Data["address"])
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+addresses[1])
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("m/44'/74'/0'/0/1", resp.Data["derivation_path"])
+
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+addresses[0]+"/sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"params": map[string]interface{}{
+			"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+			"stepLimit": "0x4a817c800",
+			"value":     "0x2386f26fc10000",
+			"timestamp": "0x185cf742ec0",
+		},
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.NotEqual("", resp.Data["signature"])
+}
+
+func TestHDWalletInvalidMnemonic(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "hd")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name":     "invalid",
+		"mnemonic": "abandon abandon abandon",
+	}
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal(t, "invalid BIP-39 mnemonic", err.Error())
+}
+
+func TestHDDeriveOverwriteKeepsState(t *testing.T) {
+	b, storage := getBackend(t)
+	call := func(operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		req := logical.TestRequest(t, operation, path)
+		req.Storage = storage
+		req.Data = data
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	storeLegacyHDWallet(t, b, storage, "treasury")
+	address := call(logical.UpdateOperation, "hd/treasury/derive", map[string]interface{}{"alias_name": "cold"}).Data["address"].(string)
+	call(logical.UpdateOperation, "accounts/"+address+"/sign_hash/enable", nil)
+	call(logical.UpdateOperation, "accounts/"+address+"/disable", nil)
+
+	// deriving the same key again only reassigns the alias
+	resp := call(logical.UpdateOperation, "hd/treasury/derive", map[string]interface{}{
+		"index":      0,
+		"alias_name": "hot",
+		"exportable": true,
+		"overwrite":  true,
+	})
+	assert.Equal(t, address, resp.Data["address"])
+
+	resp = call(logical.ReadOperation, "accounts/"+address, nil)
+	assert.Equal(t, "hot", resp.Data["alias_name"])
+	assert.Equal(t, AccountStatusDisabled, resp.Data["status"])
+	assert.Equal(t, true, resp.Data["allow_sign_hash"])
+	account, _ := b.(*backend).retrieveAccount(context.Background(), &logical.Request{Storage: storage}, address)
+	assert.False(t, account.Exportable)
+	assert.Equal(t, AccountStatusDisabled, account.Status)
+}
+
+func TestHDDeriveConcurrent(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "hd")
+	req.Storage = storage
+	req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+	req.Data = map[string]interface{}{"name": "treasury"}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	const count = 8
+	addresses := make(chan string, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := logical.TestRequest(t, logical.UpdateOperation, "hd/treasury/derive")
+			req.Storage = storage
+			req.Data = map[string]interface{}{"exportable": true}
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err != nil {
+				t.Errorf("err: %v", err)
+				return
+			}
+			addresses <- resp.Data["address"].(string)
+		}()
+	}
+	wg.Wait()
+	close(addresses)
+
+	// every derivation got its own index
+	derived := map[string]bool{}
+	for address := range addresses {
+		derived[address] = true
+	}
+	assert.Equal(count, len(derived))
+	wallet, err := b.(*backend).retrieveHDWallet(context.Background(), req, "treasury")
+	assert.Nil(err)
+	assert.Equal(uint32(count), wallet.NextIndex)
+}
+
+// storeLegacyHDWallet stores a wallet the way it was stored before Exported
+// was set on creation, so that non-exportable accounts can be derived from it.
+func storeLegacyHDWallet(t *testing.T, b logical.Backend, storage logical.Storage, name string) {
+	seed := bip39.NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", name)
+	wallet := &HDWallet{
+		Name:      name,
+		Seed:      hex.EncodeToString(seed),
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := b.(*backend).storeHDWallet(context.Background(), &logical.Request{Storage: storage}, wallet); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -1,12 +1,14 @@
 package backend
 
 import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/haltingstate/secp256k1-go"
-	secp256k1_go "github.com/haltingstate/secp256k1-go/secp256k1-go2"
 	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 const (
@@ -32,12 +34,78 @@ const (
 	Version3 = 3
 )
 
+const (
+	// AccountStatusActive is the status of an account that can sign
+	AccountStatusActive = "active"
+	// AccountStatusRetired is the status of an account replaced by a rotation
+	AccountStatusRetired = "retired"
+	// AccountStatusDisabled is the status of an account that can be read but cannot sign
+	AccountStatusDisabled = "disabled"
+	// AccountStatusPendingDeletion is the status of a deleted account that can
+	// still be restored until its grace period ends
+	AccountStatusPendingDeletion = "pending_deletion"
+)
+
+const (
+	// KeyTypeSECP256K1 is the key type of ICON wallet accounts
+	KeyTypeSECP256K1 = "secp256k1"
+	// KeyTypeBLS12381 is the key type of P-Rep node BLS keys
+	KeyTypeBLS12381 = "bls12-381"
+)
+
 // Account is an ICON account
 type Account struct {
 	Address    string `json:"address"`
 	PrivateKey string `json:"private_key"`
 	PublicKey  string `json:"public_key"`
 	AliasName  string `json:"alias_name"`
+	// HDWallet and DerivationPath are set for accounts derived from an HD wallet
+	HDWallet       string `json:"hd_wallet,omitempty"`
+	DerivationPath string `json:"derivation_path,omitempty"`
+	// KeyType is empty for the secp256k1 accounts stored before it was introduced
+	KeyType string `json:"key_type,omitempty"`
+	// Exportable is set at creation time and allows the key to be exported
+	Exportable bool `json:"exportable"`
+	// AllowSignHash allows signing raw 32-byte digests with sign_hash
+	AllowSignHash bool `json:"allow_sign_hash,omitempty"`
+	// AllowKeyAgreement allows decrypting and deriving ECDH secrets with the key
+	AllowKeyAgreement bool   `json:"allow_key_agreement,omitempty"`
+	Status            string `json:"status"`
+	CreatedAt         int64  `json:"created_at"`
+	// RotatedFrom and RotatedTo link the accounts of a key rotation
+	RotatedFrom string `json:"rotated_from,omitempty"`
+	RotatedTo   string `json:"rotated_to,omitempty"`
+	// DeleteAfter is the unix time after which a pending deletion is purged
+	DeleteAfter int64 `json:"delete_after,omitempty"`
+	// PreviousStatus is the status of a pending deletion before it was deleted, which a restore returns to
+	PreviousStatus string `json:"previous_status,omitempty"`
+	// Descriptive metadata, editable after creation
+	Description string            `json:"description,omitempty"`
+	OwnerTeam   string            `json:"owner_team,omitempty"`
+	Purpose     string            `json:"purpose,omitempty"`
+	CostCenter  string            `json:"cost_center,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	UpdatedAt   int64             `json:"updated_at,omitempty"`
+	UpdatedBy   string            `json:"updated_by,omitempty"`
+}
+
+// IsActive returns whether the account can sign. Accounts stored before
+// the status was introduced have no status and are active.
+func (a *Account) IsActive() bool {
+	return a.Status == "" || a.Status == AccountStatusActive
+}
+
+// IsSECP256K1 returns whether the account is an ICON wallet key that signs transactions.
+func (a *Account) IsSECP256K1() bool {
+	return a.KeyType == "" || a.KeyType == KeyTypeSECP256K1
+}
+
+// keyType returns the key type, defaulting to secp256k1.
+func (a *Account) keyType() string {
+	if a.KeyType == "" {
+		return KeyTypeSECP256K1
+	}
+	return a.KeyType
 }
 
 type PrivateKey struct {
@@ -45,7 +113,7 @@ type PrivateKey struct {
 }
 
 type PublicKey struct {
-	bytes []byte // 33-byte compressed format to use halting state library efficiently
+	bytes []byte // 33-byte compressed format
 }
 
 var (
@@ -85,12 +153,18 @@ func (key *PublicKey) String() string {
 	return "0x" + hex.EncodeToString(key.bytes)
 }
 
+// SerializeUncompressed returns the 65-byte uncompressed form of the key, or
+// nil if the key is not a point on the curve.
 func (key *PublicKey) SerializeUncompressed() []byte {
-	return secp256k1.UncompressPubkey(key.bytes)
+	pub, err := secp256k1.ParsePubKey(key.bytes)
+	if err != nil {
+		return nil
+	}
+	return pub.SerializeUncompressed()
 }
 
 func (key *PrivateKey) PublicKey() *PublicKey {
-	pkBytes := secp256k1.PubkeyFromSeckey(key.bytes)
+	pkBytes := secp256k1.PrivKeyFromBytes(key.bytes).PubKey().SerializeCompressed()
 	pk, err := ParsePublicKey(pkBytes)
 	if err != nil {
 		panic(err)
@@ -108,9 +182,12 @@ func (key *PublicKey) Address() string {
 }
 
 func GenerateKey() (*PrivateKey, *PublicKey) {
-	pub, priv := secp256k1.GenerateKeyPair()
-	privKey := &PrivateKey{priv}
-	pubKey, _ := ParsePublicKey(pub)
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		panic(err)
+	}
+	privKey := &PrivateKey{priv.Serialize()}
+	pubKey, _ := ParsePublicKey(priv.PubKey().SerializeCompressed())
 	//address := PublicKeyExtractAddress(pub)
 	return privKey, pubKey
 }
@@ -141,7 +218,9 @@ func ParsePrivateKey(b []byte) (*PrivateKey, error) {
 	if len(b) != PrivateKeyLen {
 		return nil, errors.New("InvalidKeyLength")
 	}
-	if secp256k1_go.SeckeyIsValid(b) != 1 {
+	// the key must be in [1, N-1]
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(b); overflow || scalar.IsZero() {
 		return nil, errors.New("InvalidSeckey")
 	}
 
@@ -166,6 +245,60 @@ func ParsePublicKey(pubKey []byte) (*PublicKey, error) {
 	}
 }
 
+var (
+	oidPublicKeyECDSA      = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// subjectPublicKeyInfo is the X.509 SubjectPublicKeyInfo of RFC 5280
+type subjectPublicKeyInfo struct {
+	Raw       asn1.RawContent
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// MarshalPKIX returns the DER SubjectPublicKeyInfo of the key, with the
+// secp256k1 named curve and the uncompressed point.
+func (key *PublicKey) MarshalPKIX() ([]byte, error) {
+	point := key.SerializeUncompressed()
+	if point == nil {
+		return nil, errors.New("public key is not on the curve")
+	}
+	curve, err := asn1.Marshal(oidNamedCurveSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyECDSA,
+			Parameters: asn1.RawValue{FullBytes: curve},
+		},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+// ParsePKIXPublicKey parses a DER SubjectPublicKeyInfo of a secp256k1 key.
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var info subjectPublicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after the public key")
+	}
+	var curve asn1.ObjectIdentifier
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, errors.New("public key is not an EC key")
+	}
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil || !curve.Equal(oidNamedCurveSecp256k1) {
+		return nil, errors.New("public key is not a secp256k1 key")
+	}
+	point := info.PublicKey.RightAlign()
+	if _, err := secp256k1.ParsePubKey(point); err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(point)
+}
+
 func ParsePrivateKeyFromString(privateKeyStr string) (*PrivateKey, error) {
 	privateKeyStr = strings.Replace(privateKeyStr, "0x", "", -1)
 	privateKey, decodeErr := hex.DecodeString(privateKeyStr)
@@ -180,19 +313,29 @@ func ParsePrivateKeyFromString(privateKeyStr string) (*PrivateKey, error) {
 }
 
 func SignFromPrivateKey(privateKeyStr string, requestSign []byte) (string, error) {
+	signedTx, err := SignatureFromPrivateKey(privateKeyStr, requestSign)
+	if err != nil {
+		return "", err
+	}
+	b64Signature, _ := signedTx.EncodeBase64()
+	return b64Signature, err
+}
+
+// SignatureFromPrivateKey signs the SHA3-256 hash of requestSign and verifies
+// the signature before returning it.
+func SignatureFromPrivateKey(privateKeyStr string, requestSign []byte) (*Signature, error) {
 	privateKey, err := ParsePrivateKeyFromString(privateKeyStr)
 	if err != nil {
-		return "", fmt.Errorf("[ERROR] ParsePrivateKeyFromString %s", err)
+		return nil, fmt.Errorf("[ERROR] ParsePrivateKeyFromString %s", err)
 	}
 	requestSignBytes := SHA3Sum256(requestSign)
 	signedTx, err := NewSignature(requestSignBytes, privateKey)
 	if err != nil {
-		return "", fmt.Errorf("[ERROR] NewSignature error -  %s", err)
+		return nil, fmt.Errorf("[ERROR] NewSignature error -  %s", err)
 	}
 	verifySign := signedTx.Verify(requestSignBytes, privateKey.PublicKey())
 	if verifySign == false {
-		return "", fmt.Errorf("[ERROR] Failed to Verify the Transaction")
+		return nil, fmt.Errorf("[ERROR] Failed to Verify the Transaction")
 	}
-	b64Signature, _ := signedTx.EncodeBase64()
-	return b64Signature, err
+	return signedTx, nil
 }
@@ -0,0 +1,306 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// accountIndexPrefix holds one index entry per account address
+	accountIndexPrefix = "index/accounts/"
+	// accountIndexBuiltPath is written once the existing accounts are indexed
+	accountIndexBuiltPath  = "index/accounts_built"
+	legacyAccountIndexPath = "index/accounts"
+
+	// defaultAccountPageSize is the number of index entries read for a page
+	// when the filter has no limit
+	defaultAccountPageSize = 100
+)
+
+// accountIndexEntry holds the non-secret fields of an account, so that
+// listing does not need to read every account
+type accountIndexEntry struct {
+	AliasName   string            `json:"alias_name"`
+	PublicKey   string            `json:"public_key"`
+	KeyType     string            `json:"key_type,omitempty"`
+	HDWallet    string            `json:"hd_wallet,omitempty"`
+	Exportable  bool              `json:"exportable,omitempty"`
+	Status      string            `json:"status"`
+	CreatedAt   int64             `json:"created_at"`
+	DeleteAfter int64             `json:"delete_after,omitempty"`
+	Description string            `json:"description,omitempty"`
+	OwnerTeam   string            `json:"owner_team,omitempty"`
+	Purpose     string            `json:"purpose,omitempty"`
+	CostCenter  string            `json:"cost_center,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+func newAccountIndexEntry(account *Account) *accountIndexEntry {
+	return &accountIndexEntry{
+		AliasName:   account.AliasName,
+		PublicKey:   account.PublicKey,
+		KeyType:     account.keyType(),
+		HDWallet:    account.HDWallet,
+		Exportable:  account.Exportable,
+		Status:      account.Status,
+		CreatedAt:   account.CreatedAt,
+		DeleteAfter: account.DeleteAfter,
+		Description: account.Description,
+		OwnerTeam:   account.OwnerTeam,
+		Purpose:     account.Purpose,
+		CostCenter:  account.CostCenter,
+		Labels:      account.Labels,
+	}
+}
+
+func (e *accountIndexEntry) keyType() string {
+	if e.KeyType == "" {
+		return KeyTypeSECP256K1
+	}
+	return e.KeyType
+}
+
+func (e *accountIndexEntry) isActive() bool {
+	return e.Status == "" || e.Status == AccountStatusActive
+}
+
+// keyInfo returns the entry in the format of the detailed list response.
+func (e *accountIndexEntry) keyInfo() map[string]interface{} {
+	labels := e.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return map[string]interface{}{
+		"alias_name":  e.AliasName,
+		"public_key":  e.PublicKey,
+		"key_type":    e.keyType(),
+		"status":      e.Status,
+		"created_at":  e.CreatedAt,
+		"description": e.Description,
+		"owner_team":  e.OwnerTeam,
+		"purpose":     e.Purpose,
+		"cost_center": e.CostCenter,
+		"labels":      labels,
+	}
+}
+
+// accountFilter selects accounts from the index
+type accountFilter struct {
+	After         string
+	Limit         int
+	AliasPrefix   string
+	Labels        map[string]string
+	Status        string
+	HDWallet      string
+	CreatedAfter  int64
+	CreatedBefore int64
+}
+
+func (f *accountFilter) match(address string, e *accountIndexEntry) bool {
+	if f.After != "" && address <= f.After {
+		return false
+	}
+	if f.AliasPrefix != "" && !strings.HasPrefix(e.AliasName, f.AliasPrefix) {
+		return false
+	}
+	for k, v := range f.Labels {
+		if e.Labels[k] != v {
+			return false
+		}
+	}
+	if f.Status != "" && e.Status != f.Status {
+		return false
+	}
+	if f.HDWallet != "" && e.HDWallet != f.HDWallet {
+		return false
+	}
+	if f.CreatedAfter > 0 && e.CreatedAt < f.CreatedAfter {
+		return false
+	}
+	if f.CreatedBefore > 0 && e.CreatedAt >= f.CreatedBefore {
+		return false
+	}
+	return true
+}
+
+// listAccountIndex returns a page of the sorted addresses matching the
+// filter along with their index entries. Only the entries after filter.After
+// are read, up to filter.Limit or defaultAccountPageSize of them. When more
+// entries are left, next is the last address read, from which the following
+// page continues even if the filter dropped every entry of this one.
+func (b *backend) listAccountIndex(ctx context.Context, req *logical.Request, filter *accountFilter) ([]string, map[string]*accountIndexEntry, string, error) {
+	if err := b.buildAccountIndex(ctx, req); err != nil {
+		return nil, nil, "", err
+	}
+	addresses, err := req.Storage.List(ctx, accountIndexPrefix)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the account index", "error", err)
+		return nil, nil, "", err
+	}
+	sort.Strings(addresses)
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAccountPageSize
+	}
+	var keys []string
+	var last, next string
+	read := 0
+	entries := map[string]*accountIndexEntry{}
+	for _, address := range addresses {
+		if filter.After != "" && address <= filter.After {
+			continue
+		}
+		if read >= limit {
+			next = last
+			break
+		}
+		read++
+		last = address
+		entry, err := b.retrieveAccountIndexEntry(ctx, req, address)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if entry != nil && filter.match(address, entry) {
+			keys = append(keys, address)
+			entries[address] = entry
+		}
+	}
+	return keys, entries, next, nil
+}
+
+// listAllAccountIndex reads every page of the index matching the filter.
+func (b *backend) listAllAccountIndex(ctx context.Context, req *logical.Request, filter *accountFilter) ([]string, map[string]*accountIndexEntry, error) {
+	var keys []string
+	entries := map[string]*accountIndexEntry{}
+	page := *filter
+	for {
+		pageKeys, pageEntries, next, err := b.listAccountIndex(ctx, req, &page)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, pageKeys...)
+		for address, entry := range pageEntries {
+			entries[address] = entry
+		}
+		if next == "" {
+			return keys, entries, nil
+		}
+		page.After = next
+	}
+}
+
+func (b *backend) retrieveAccountIndexEntry(ctx context.Context, req *logical.Request, address string) (*accountIndexEntry, error) {
+	entry, err := req.Storage.Get(ctx, accountIndexPrefix+address)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the account index entry", "address", address, "error", err)
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	var indexEntry accountIndexEntry
+	if err := entry.DecodeJSON(&indexEntry); err != nil {
+		return nil, err
+	}
+	return &indexEntry, nil
+}
+
+// buildAccountIndex indexes the stored accounts if it has not been done yet.
+func (b *backend) buildAccountIndex(ctx context.Context, req *logical.Request) error {
+	b.indexLock.Lock()
+	defer b.indexLock.Unlock()
+	if b.indexBuilt {
+		return nil
+	}
+	built, err := req.Storage.Get(ctx, accountIndexBuiltPath)
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the account index", "error", err)
+		return err
+	}
+	if built != nil {
+		b.indexBuilt = true
+		return nil
+	}
+
+	b.Logger().Info("Build the account index")
+	vals, err := req.Storage.List(ctx, "accounts/")
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of accounts", "error", err)
+		return err
+	}
+	for _, address := range vals {
+		account, err := b.retrieveAccount(ctx, req, address)
+		if err != nil {
+			return err
+		}
+		if account == nil || account.Address == "" {
+			continue
+		}
+		if err := b.storeAccountIndexEntry(ctx, req, account); err != nil {
+			return err
+		}
+	}
+	// the index used to be a single entry holding every account
+	if err := req.Storage.Delete(ctx, legacyAccountIndexPath); err != nil {
+		return err
+	}
+	entry, err := logical.StorageEntryJSON(accountIndexBuiltPath, map[string]interface{}{
+		"accounts": len(vals),
+	})
+	if err != nil {
+		return err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return err
+	}
+	b.indexBuilt = true
+	return nil
+}
+
+func (b *backend) storeAccountIndexEntry(ctx context.Context, req *logical.Request, account *Account) error {
+	entry, err := logical.StorageEntryJSON(accountIndexPrefix+account.Address, newAccountIndexEntry(account))
+	if err != nil {
+		return err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		b.Logger().Error("[ERROR] Failed to save the account index entry", "address", account.Address, "error", err)
+		return err
+	}
+	return nil
+}
+
+// indexAccount adds or replaces the entry of the account in the index.
+func (b *backend) indexAccount(ctx context.Context, req *logical.Request, account *Account) error {
+	// an index built later reads the account itself
+	if err := b.buildAccountIndex(ctx, req); err != nil {
+		return err
+	}
+	return b.storeAccountIndexEntry(ctx, req, account)
+}
+
+// unindexAccount removes the entry of the address from the index.
+func (b *backend) unindexAccount(ctx context.Context, req *logical.Request, address string) error {
+	if err := req.Storage.Delete(ctx, accountIndexPrefix+address); err != nil {
+		b.Logger().Error("[ERROR] Failed to delete the account index entry", "address", address, "error", err)
+		return err
+	}
+	return nil
+}
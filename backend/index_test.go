@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAccountsFilter(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	var addresses []string
+	for i := 0; i < 5; i++ {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+		req.Storage = storage
+		req.Data = map[string]interface{}{
+			"name": fmt.Sprintf("team-%d", i),
+		}
+		if i >= 3 {
+			req.Data["name"] = fmt.Sprintf("ops-%d", i)
+		}
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		addresses = append(addresses, resp.Data["address"].(string))
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+addresses[0])
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"labels": map[string]interface{}{"env": "prod"},
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	list := func(data map[string]interface{}) *logical.Response {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts")
+		req.Storage = storage
+		req.Data = data
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+
+	resp := list(map[string]interface{}{"alias_prefix": "ops-"})
+	assert.ElementsMatch(addresses[3:], resp.Data["keys"])
+
+	resp = list(map[string]interface{}{"labels": map[string]interface{}{"env": "prod"}})
+	assert.Equal([]string{addresses[0]}, resp.Data["keys"])
+	info := resp.Data["key_info"].(map[string]interface{})[addresses[0]].(map[string]interface{})
+	assert.Equal("team-0", info["alias_name"])
+	assert.NotEqual("", info["public_key"])
+	assert.NotEqual(int64(0), info["created_at"])
+
+	resp = list(map[string]interface{}{"status": AccountStatusRetired})
+	assert.Nil(resp.Data["keys"])
+	assert.Nil(resp.Data["next"])
+
+	// paging through the accounts returns each account once, also when the
+	// filter drops every account of a page
+	for prefix, expected := range map[string][]string{"": addresses, "ops-": addresses[3:]} {
+		var paged []string
+		after := ""
+		for pages := 0; pages < len(addresses); pages++ {
+			resp = list(map[string]interface{}{"after": after, "limit": 2, "alias_prefix": prefix, "detail": false})
+			keys, _ := resp.Data["keys"].([]string)
+			assert.True(len(keys) <= 2)
+			paged = append(paged, keys...)
+			next, ok := resp.Data["next"].(string)
+			if !ok {
+				break
+			}
+			after = next
+		}
+		assert.ElementsMatch(expected, paged, prefix)
+	}
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "accounts/"+addresses[1])
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp = list(map[string]interface{}{"status": AccountStatusPendingDeletion})
+	assert.Equal([]string{addresses[1]}, resp.Data["keys"])
+	resp = list(map[string]interface{}{"alias_prefix": "team-", "status": AccountStatusActive})
+	assert.ElementsMatch([]string{addresses[0], addresses[2]}, resp.Data["keys"])
+}
+
+func TestAccountIndexRebuild(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	// accounts stored before the index existed are picked up on the next
+	// listing after a restart
+	for _, path := range []string{accountIndexBuiltPath, accountIndexPrefix + address} {
+		if err := storage.Delete(context.Background(), path); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	if err := storage.Put(context.Background(), &logical.StorageEntry{Key: legacyAccountIndexPath, Value: []byte("{}")}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b.(*backend).indexBuilt = false
+	req = logical.TestRequest(t, logical.ListOperation, "accounts")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal([]string{address}, resp.Data["keys"])
+
+	entry, err := storage.Get(context.Background(), accountIndexPrefix+address)
+	assert.Nil(err)
+	assert.NotNil(entry)
+	entry, err = storage.Get(context.Background(), legacyAccountIndexPath)
+	assert.Nil(err)
+	assert.Nil(entry)
+}
@@ -0,0 +1,245 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// JWTAlgorithm is the JWS algorithm of ECDSA on secp256k1 with SHA-256, RFC 8812
+	JWTAlgorithm = "ES256K"
+	// DefaultJWTMaxTTL is the maximum lifetime of an issued JWT, 1 hour
+	DefaultJWTMaxTTL = 60 * 60
+)
+
+// jwtReservedClaims are set by the plugin and cannot be supplied by the caller
+var jwtReservedClaims = []string{"iss", "aud", "iat", "nbf", "exp"}
+
+// SignJWT returns a compact JWS of the claims signed with ES256K.
+func SignJWT(claims map[string]interface{}, kid string, privateKey *PrivateKey) (string, error) {
+	header, err := json.Marshal(map[string]interface{}{
+		"alg": JWTAlgorithm,
+		"typ": "JWT",
+		"kid": kid,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := NewSignature(hash[:], privateKey)
+	if err != nil {
+		return "", err
+	}
+	rs, err := signature.SerializeRS()
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(rs), nil
+}
+
+// VerifyJWT checks the ES256K signature and the expiration of the token and
+// returns its claims.
+func VerifyJWT(token string, publicKey *PublicKey) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+	var header map[string]interface{}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header["alg"] != JWTAlgorithm {
+		return nil, fmt.Errorf("unsupported JWT algorithm - %v", header["alg"])
+	}
+	rs, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(rs) != SignatureLenRaw {
+		return nil, errors.New("malformed JWT signature")
+	}
+	signature, _ := ParseSignature(rs)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !signature.Verify(hash[:], publicKey) {
+		return nil, errors.New("invalid JWT signature")
+	}
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if exp, ok := claims["exp"].(float64); !ok || int64(exp) <= time.Now().Unix() {
+		return nil, errors.New("JWT has expired")
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("malformed JWT - %v", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("malformed JWT - %v", err)
+	}
+	return nil
+}
+
+// JWK returns the JSON Web Key of the public key, RFC 8812.
+func JWK(publicKey *PublicKey, kid string) (map[string]interface{}, error) {
+	uncompressed := publicKey.SerializeUncompressed()
+	if len(uncompressed) != PublicKeyLenUncompressed {
+		return nil, errors.New("invalid public key")
+	}
+	return map[string]interface{}{
+		"kty": "EC",
+		"crv": "secp256k1",
+		"x":   base64.RawURLEncoding.EncodeToString(uncompressed[1:33]),
+		"y":   base64.RawURLEncoding.EncodeToString(uncompressed[33:]),
+		"use": "sig",
+		"alg": JWTAlgorithm,
+		"kid": kid,
+	}, nil
+}
+
+// checkJWTAudiences returns an error if an audience is not allowed. Tokens
+// must name an audience, and no token is issued before the allowed audiences
+// are configured.
+func checkJWTAudiences(audiences, allowed []string) error {
+	if len(allowed) == 0 {
+		return errors.New("jwt_audiences must be configured before a JWT can be issued")
+	}
+	if len(audiences) == 0 {
+		return errors.New("audience is required")
+	}
+	for _, audience := range audiences {
+		found := false
+		for _, a := range allowed {
+			if audience == a {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("audience %s is not allowed", audience)
+		}
+	}
+	return nil
+}
+
+func (b *backend) issueJWT(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ttl := int64(data.Get("ttl").(int))
+	if ttl <= 0 {
+		ttl = config.JWTMaxTTL
+	}
+	if ttl > config.JWTMaxTTL {
+		return nil, fmt.Errorf("ttl exceeds jwt_max_ttl of %d seconds", config.JWTMaxTTL)
+	}
+	audiences := data.Get("audience").([]string)
+	if err := checkJWTAudiences(audiences, config.JWTAudiences); err != nil {
+		return nil, err
+	}
+	claims := map[string]interface{}{}
+	for key, value := range data.Get("claims").(map[string]interface{}) {
+		claims[key] = value
+	}
+	for _, reserved := range jwtReservedClaims {
+		if _, ok := claims[reserved]; ok {
+			return nil, fmt.Errorf("claim %s is set by the plugin", reserved)
+		}
+	}
+
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	claims["iss"] = account.Address
+	claims["iat"] = now
+	claims["exp"] = now + ttl
+	if len(audiences) == 1 {
+		claims["aud"] = audiences[0]
+	} else {
+		claims["aud"] = audiences
+	}
+	token, err := SignJWT(claims, account.Address, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[JWT][OK] Issued a token", "address", account.Address, "audience", audiences, "ttl", ttl)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"token":      token,
+			"kid":        account.Address,
+			"expires_at": now + ttl,
+		},
+	}, nil
+}
+
+// retrievePublicKey returns a secp256k1 account and its public key. The
+// feature names what is not supported for other key types.
+func (b *backend) retrievePublicKey(ctx context.Context, req *logical.Request, name, feature string) (*Account, *PublicKey, error) {
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account == nil {
+		return nil, nil, fmt.Errorf("Account does not exist - %s", name)
+	}
+	if !account.IsSECP256K1() {
+		return nil, nil, fmt.Errorf("%s is not supported for %s keys", feature, account.KeyType)
+	}
+	pubBytes, err := hex.DecodeString(strings.TrimPrefix(account.PublicKey, "0x"))
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := ParsePublicKey(pubBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, publicKey, nil
+}
+
+func (b *backend) readJWKS(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	account, publicKey, err := b.retrievePublicKey(ctx, req, data.Get("name").(string), "JWKS")
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := JWK(publicKey, account.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"keys": []map[string]interface{}{jwk},
+		},
+	}, nil
+}
@@ -0,0 +1,108 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueJWT(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	issueJWT := func(data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/jwt")
+		req.Storage = storage
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+	_, err = issueJWT(map[string]interface{}{"audience": "payments"})
+	assert.EqualError(t, err, "jwt_audiences must be configured before a JWT can be issued")
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "config")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"jwt_max_ttl":   "10m",
+		"jwt_audiences": "payments,ledger",
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	resp, err := issueJWT(map[string]interface{}{
+		"claims":   map[string]interface{}{"sub": "settlement"},
+		"audience": "payments",
+		"ttl":      "5m",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	token := resp.Data["token"].(string)
+	assert.Equal(t, address, resp.Data["kid"])
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address+"/jwks")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	jwk := resp.Data["keys"].([]map[string]interface{})[0]
+	assert.Equal(t, "EC", jwk["kty"])
+	assert.Equal(t, "secp256k1", jwk["crv"])
+	assert.Equal(t, "ES256K", jwk["alg"])
+	assert.Equal(t, address, jwk["kid"])
+	x, _ := base64.RawURLEncoding.DecodeString(jwk["x"].(string))
+	y, _ := base64.RawURLEncoding.DecodeString(jwk["y"].(string))
+	publicKey, err := ParsePublicKey(append(append([]byte{0x04}, x...), y...))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, publicKey.Address())
+
+	claims, err := VerifyJWT(token, publicKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "settlement", claims["sub"])
+	assert.Equal(t, "payments", claims["aud"])
+	assert.Equal(t, address, claims["iss"])
+	assert.Equal(t, float64(300), claims["exp"].(float64)-claims["iat"].(float64))
+
+	_, otherKey := GenerateKey()
+	_, err = VerifyJWT(token, otherKey)
+	assert.EqualError(t, err, "invalid JWT signature")
+
+	_, err = issueJWT(map[string]interface{}{"audience": "payments", "ttl": "1h"})
+	assert.EqualError(t, err, "ttl exceeds jwt_max_ttl of 600 seconds")
+	_, err = issueJWT(map[string]interface{}{"audience": "treasury"})
+	assert.EqualError(t, err, "audience treasury is not allowed")
+	_, err = issueJWT(map[string]interface{}{})
+	assert.EqualError(t, err, "audience is required")
+	_, err = issueJWT(map[string]interface{}{
+		"audience": "ledger",
+		"claims":   map[string]interface{}{"exp": 1},
+	})
+	assert.EqualError(t, err, "claim exp is set by the plugin")
+}
@@ -0,0 +1,302 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	KeyStoreVersion   = 3
+	KeyStoreCipher    = "aes-128-ctr"
+	KeyStoreKDFScrypt = "scrypt"
+	KeyStoreKDFPBKDF2 = "pbkdf2"
+
+	keyStoreDKLen     = 32
+	keyStoreScryptN   = 1 << 14
+	keyStoreScryptR   = 8
+	keyStoreScryptP   = 1
+	keyStorePBKDF2C   = 262144
+	keyStorePBKDF2PRF = "hmac-sha256"
+
+	// upper bounds of the KDF parameters accepted on import, so that a crafted
+	// keystore cannot make the plugin spend unbounded memory or time. N stops
+	// at the standard scrypt cost of geth, 256 MiB with r = 8.
+	keyStoreMaxScryptN = 1 << 18
+	keyStoreMaxScryptR = 8
+	keyStoreMaxScryptP = 16
+	keyStoreMaxPBKDF2C = 10000000
+)
+
+// KeyStore is the keystore v3 JSON format used by the ICON SDKs and goloop
+type KeyStore struct {
+	Address  string         `json:"address"`
+	Crypto   KeyStoreCrypto `json:"crypto"`
+	ID       string         `json:"id"`
+	Version  int            `json:"version"`
+	CoinType string         `json:"coinType"`
+}
+
+type KeyStoreCrypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherParams KeyStoreCipherParams   `json:"cipherparams"`
+	CipherText   string                 `json:"ciphertext"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type KeyStoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// EncryptKeyStore encrypts the private key into a keystore with the given KDF.
+func EncryptKeyStore(privateKey *PrivateKey, passphrase, kdf string) (*KeyStore, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	var kdfParams map[string]interface{}
+	switch kdf {
+	case KeyStoreKDFScrypt:
+		kdfParams = map[string]interface{}{
+			"dklen": keyStoreDKLen,
+			"n":     keyStoreScryptN,
+			"r":     keyStoreScryptR,
+			"p":     keyStoreScryptP,
+			"salt":  hex.EncodeToString(salt),
+		}
+	case KeyStoreKDFPBKDF2:
+		kdfParams = map[string]interface{}{
+			"dklen": keyStoreDKLen,
+			"c":     keyStorePBKDF2C,
+			"prf":   keyStorePBKDF2PRF,
+			"salt":  hex.EncodeToString(salt),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kdf - %s", kdf)
+	}
+	derivedKey, err := keyStoreDerivedKey(passphrase, kdf, kdfParams)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherText, err := aesCTRXOR(derivedKey[:16], privateKey.Bytes(), iv)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	return &KeyStore{
+		Address: privateKey.PublicKey().Address(),
+		Crypto: KeyStoreCrypto{
+			Cipher:       KeyStoreCipher,
+			CipherParams: KeyStoreCipherParams{IV: hex.EncodeToString(iv)},
+			CipherText:   hex.EncodeToString(cipherText),
+			KDF:          kdf,
+			KDFParams:    kdfParams,
+			MAC:          hex.EncodeToString(keyStoreMAC(derivedKey, cipherText)),
+		},
+		ID:       id,
+		Version:  KeyStoreVersion,
+		CoinType: "icx",
+	}, nil
+}
+
+// DecryptKeyStore checks the MAC and the address of the keystore and returns
+// the decrypted private key.
+func DecryptKeyStore(ks *KeyStore, passphrase string) (*PrivateKey, error) {
+	if ks.Version != KeyStoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version - %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != KeyStoreCipher {
+		return nil, fmt.Errorf("unsupported cipher - %s", ks.Crypto.Cipher)
+	}
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipherparams.iv")
+	}
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext")
+	}
+	mac, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mac")
+	}
+
+	derivedKey, err := keyStoreDerivedKey(passphrase, ks.Crypto.KDF, ks.Crypto.KDFParams)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(keyStoreMAC(derivedKey, cipherText), mac) != 1 {
+		return nil, errors.New("MAC mismatch, wrong passphrase or corrupted keystore")
+	}
+
+	plain, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := ParsePrivateKey(plain)
+	if err != nil {
+		return nil, err
+	}
+	address := privateKey.PublicKey().Address()
+	if ks.Address != "" && ks.Address != address {
+		return nil, fmt.Errorf("keystore address mismatch, keystore=%s, derived=%s", ks.Address, address)
+	}
+	return privateKey, nil
+}
+
+func keyStoreDerivedKey(passphrase, kdf string, params map[string]interface{}) ([]byte, error) {
+	salt, err := hex.DecodeString(kdfParamString(params, "salt"))
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("invalid kdfparams.salt")
+	}
+	dkLen := kdfParamInt(params, "dklen")
+	if dkLen != keyStoreDKLen {
+		return nil, fmt.Errorf("invalid kdfparams.dklen - %d", dkLen)
+	}
+
+	switch kdf {
+	case KeyStoreKDFScrypt:
+		n, r, p := kdfParamInt(params, "n"), kdfParamInt(params, "r"), kdfParamInt(params, "p")
+		if n <= 1 || n > keyStoreMaxScryptN {
+			return nil, fmt.Errorf("invalid kdfparams.n - %d", n)
+		}
+		if r <= 0 || r > keyStoreMaxScryptR {
+			return nil, fmt.Errorf("invalid kdfparams.r - %d", r)
+		}
+		if p <= 0 || p > keyStoreMaxScryptP {
+			return nil, fmt.Errorf("invalid kdfparams.p - %d", p)
+		}
+		return scrypt.Key([]byte(passphrase), salt, n, r, p, dkLen)
+	case KeyStoreKDFPBKDF2:
+		if prf := kdfParamString(params, "prf"); prf != keyStorePBKDF2PRF {
+			return nil, fmt.Errorf("unsupported kdfparams.prf - %s", prf)
+		}
+		c := kdfParamInt(params, "c")
+		if c <= 0 || c > keyStoreMaxPBKDF2C {
+			return nil, fmt.Errorf("invalid kdfparams.c - %d", c)
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf - %s", kdf)
+	}
+}
+
+func keyStoreMAC(derivedKey, cipherText []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(derivedKey[16:32])
+	h.Write(cipherText)
+	return h.Sum(nil)
+}
+
+func aesCTRXOR(key, in, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+func kdfParamString(params map[string]interface{}, key string) string {
+	v, _ := params[key].(string)
+	return v
+}
+
+// kdfParamInt reads the numeric KDF parameter, which is float64 when decoded from JSON.
+func kdfParamInt(params map[string]interface{}, key string) int {
+	switch v := params[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	}
+	return 0
+}
+
+func (b *backend) importKeyStore(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	nameInput := data.Get("name").(string)
+	keyStoreInput := data.Get("keystore").(string)
+	passphrase := data.Get("passphrase").(string)
+	exportable := data.Get("exportable").(bool)
+
+	if keyStoreInput == "" {
+		return nil, fmt.Errorf("keystore is required")
+	}
+	var ks KeyStore
+	if err := json.Unmarshal([]byte(keyStoreInput), &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse the keystore: %v", err)
+	}
+	privateKey, err := DecryptKeyStore(&ks, passphrase)
+	if err != nil {
+		b.Logger().Error("[IMPORT][FAIL] Failed to decrypt the keystore", "address", ks.Address, "error", err)
+		return nil, err
+	}
+	publicKey := privateKey.PublicKey()
+	stored, err := b.checkAccountConflict(ctx, req, publicKey.Address(), nameInput, data.Get("overwrite").(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Address:    publicKey.Address(),
+		PrivateKey: privateKey.String(),
+		PublicKey:  publicKey.String(),
+		AliasName:  nameInput,
+		Exportable: exportable,
+	}
+	account.keepStoredState(stored)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[IMPORT][OK] Imported keystore", "address", account.Address)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"alias_name": account.AliasName,
+		},
+	}, nil
+}
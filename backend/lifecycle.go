@@ -0,0 +1,120 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// setAccountStatus moves the account from one of the given statuses to the new status.
+func (b *backend) setAccountStatus(ctx context.Context, req *logical.Request, name, event, status string, from ...string) (*logical.Response, error) {
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("Account does not exist - %s", name)
+	}
+	allowed := false
+	for _, s := range from {
+		if account.Status == s {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("cannot %s account %s, status is %s", event, account.Address, account.Status)
+	}
+
+	previous := account.Status
+	account.Status = status
+	account.DeleteAfter = 0
+	account.PreviousStatus = ""
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, event, account.Address, map[string]interface{}{
+		"previous_status": previous,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("Account status changed", "address", account.Address, "from", previous, "to", status)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address": account.Address,
+			"status":  account.Status,
+		},
+	}, nil
+}
+
+func (b *backend) disableAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountStatus(ctx, req, data.Get("name").(string), "disable", AccountStatusDisabled, AccountStatusActive)
+}
+
+func (b *backend) enableAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountStatus(ctx, req, data.Get("name").(string), "enable", AccountStatusActive, AccountStatusDisabled)
+}
+
+func (b *backend) restoreAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if account != nil && account.Status == AccountStatusPendingDeletion && account.DeleteAfter <= time.Now().Unix() {
+		return nil, fmt.Errorf("grace period of account %s has ended", account.Address)
+	}
+	// a disabled or retired account is restored as it was, not re-enabled
+	status := AccountStatusActive
+	if account != nil && account.PreviousStatus != "" {
+		status = account.PreviousStatus
+	}
+	return b.setAccountStatus(ctx, req, name, "restore", status, AccountStatusPendingDeletion)
+}
+
+// purgeDeletedAccounts is run periodically and removes the accounts whose
+// grace period has ended.
+func (b *backend) purgeDeletedAccounts(ctx context.Context, req *logical.Request) error {
+	addresses, index, err := b.listAllAccountIndex(ctx, req, &accountFilter{Status: AccountStatusPendingDeletion})
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	for _, address := range addresses {
+		if index[address].DeleteAfter > now {
+			continue
+		}
+		account, err := b.retrieveAccount(ctx, req, address)
+		if err != nil {
+			return err
+		}
+		// the index may lag behind a restore
+		if account == nil || account.Status != AccountStatusPendingDeletion || account.DeleteAfter > now {
+			continue
+		}
+		if err := b.purgeAccount(ctx, req, account); err != nil {
+			return err
+		}
+		if err := b.recordAudit(ctx, req, "purge", account.Address, nil); err != nil {
+			return err
+		}
+		b.Logger().Info("[DELETE][OK] Purged account", "address", account.Address, "name", account.AliasName)
+	}
+	return nil
+}
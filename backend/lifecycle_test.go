@@ -0,0 +1,183 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func signWithAccount(t *testing.T, b logical.Backend, storage logical.Storage, name string) error {
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+name+"/sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"params": map[string]interface{}{
+			"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+			"stepLimit": "0x4a817c800",
+			"value":     "0x2386f26fc10000",
+			"timestamp": "0x185cf742ec0",
+		},
+	}
+	_, err := b.HandleRequest(context.Background(), req)
+	return err
+}
+
+func TestAccountDisable(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "hot-wallet"}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/hot-wallet/disable")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	err = signWithAccount(t, b, storage, "hot-wallet")
+	assert.Equal(fmt.Sprintf("Signing account %s is %s", address, AccountStatusDisabled), err.Error())
+
+	// a disabled account can still be read
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/hot-wallet")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(AccountStatusDisabled, resp.Data["status"])
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/hot-wallet/restore")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("cannot restore account %s, status is %s", address, AccountStatusDisabled), err.Error())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/hot-wallet/enable")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(signWithAccount(t, b, storage, "hot-wallet"))
+}
+
+func TestAccountSoftDelete(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "treasury"}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "accounts/treasury")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(AccountStatusPendingDeletion, resp.Data["status"])
+	assert.True(resp.Data["delete_after"].(int64) > time.Now().Unix())
+
+	err = signWithAccount(t, b, storage, address)
+	assert.Equal(fmt.Sprintf("Signing account %s is %s", address, AccountStatusPendingDeletion), err.Error())
+
+	// the alias is kept during the grace period
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "treasury"}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("alias is already in use - treasury", err.Error())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/treasury/restore")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(signWithAccount(t, b, storage, "treasury"))
+}
+
+func TestRestoreDisabledAccount(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+	call := func(operation logical.Operation, path string) *logical.Response {
+		req := logical.TestRequest(t, operation, path)
+		req.Storage = storage
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	address := call(logical.UpdateOperation, "accounts").Data["address"].(string)
+	call(logical.UpdateOperation, "accounts/"+address+"/disable")
+	call(logical.DeleteOperation, "accounts/"+address)
+
+	// a restore returns the account to disabled, not active
+	resp := call(logical.UpdateOperation, "accounts/"+address+"/restore")
+	assert.Equal(AccountStatusDisabled, resp.Data["status"])
+	err := signWithAccount(t, b, storage, address)
+	assert.Equal(fmt.Sprintf("Signing account %s is %s", address, AccountStatusDisabled), err.Error())
+
+	// an account deleted while active is restored as active
+	call(logical.UpdateOperation, "accounts/"+address+"/enable")
+	call(logical.DeleteOperation, "accounts/"+address)
+	resp = call(logical.UpdateOperation, "accounts/"+address+"/restore")
+	assert.Equal(AccountStatusActive, resp.Data["status"])
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "treasury"}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "accounts/treasury")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// nothing is purged before the grace period ends
+	backend := b.(*backend)
+	req = &logical.Request{Storage: storage}
+	assert.Nil(backend.purgeDeletedAccounts(context.Background(), req))
+	account, err := backend.retrieveAccount(context.Background(), req, address)
+	assert.Nil(err)
+	assert.NotNil(account)
+
+	account.DeleteAfter = time.Now().Unix() - 1
+	assert.Nil(backend.storeAccount(context.Background(), req, account))
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/treasury/restore")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("grace period of account %s has ended", address), err.Error())
+
+	req = &logical.Request{Storage: storage}
+	assert.Nil(backend.purgeDeletedAccounts(context.Background(), req))
+	account, err = backend.retrieveAccount(context.Background(), req, address)
+	assert.Nil(err)
+	assert.Nil(account)
+	alias, err := backend.lookupAlias(context.Background(), req, "treasury")
+	assert.Nil(err)
+	assert.Equal("", alias)
+}
@@ -0,0 +1,254 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// MerkleRootPrefix is prepended to the tree size and the root before hashing
+	// for the signature, so that a signed root can't be taken for a transaction.
+	MerkleRootPrefix = "\x19ICON Merkle Root:\n"
+	// MaxMerklePayloads is the maximum number of payloads of a sign_merkle request
+	MaxMerklePayloads = 10000
+
+	// leaves and nodes are hashed with distinct prefixes, as in RFC 6962, so
+	// that a node can't be passed off as a leaf
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+func merkleLeafHash(payload []byte) []byte {
+	return SHA3Sum256(append([]byte{merkleLeafPrefix}, payload...))
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(data, merkleNodePrefix)
+	data = append(data, left...)
+	return SHA3Sum256(append(data, right...))
+}
+
+// BuildMerkleTree returns the SHA3-256 Merkle root of the payloads and the
+// inclusion proof of each payload. The last node of an odd level is promoted
+// to the next level rather than paired with itself.
+func BuildMerkleTree(payloads [][]byte) ([]byte, [][][]byte) {
+	if len(payloads) == 0 {
+		return nil, nil
+	}
+	level := make([][]byte, len(payloads))
+	for i, payload := range payloads {
+		level[i] = merkleLeafHash(payload)
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, merkleNodeHash(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	proofs := make([][][]byte, len(payloads))
+	for i := range payloads {
+		index := i
+		for _, level := range levels[:len(levels)-1] {
+			if sibling := index ^ 1; sibling < len(level) {
+				proofs[i] = append(proofs[i], level[sibling])
+			}
+			index /= 2
+		}
+	}
+	return level[0], proofs
+}
+
+// VerifyMerkleProof returns whether the payload is at the index of a tree of
+// the size with the root.
+func VerifyMerkleProof(payload []byte, index, size int, proof [][]byte, root []byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	hash := merkleLeafHash(payload)
+	for ; size > 1; size = (size + 1) / 2 {
+		if sibling := index ^ 1; sibling < size {
+			if len(proof) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				hash = merkleNodeHash(hash, proof[0])
+			} else {
+				hash = merkleNodeHash(proof[0], hash)
+			}
+			proof = proof[1:]
+		}
+		index /= 2
+	}
+	return len(proof) == 0 && bytes.Equal(hash, root)
+}
+
+// HashMerkleRoot returns the hash signed for a tree of the size with the root.
+func HashMerkleRoot(root []byte, size int) []byte {
+	data := make([]byte, 0, len(MerkleRootPrefix)+8+len(root))
+	data = append(data, MerkleRootPrefix...)
+	data = binary.BigEndian.AppendUint64(data, uint64(size))
+	return SHA3Sum256(append(data, root...))
+}
+
+func decodeMerkleProof(proof []string) ([][]byte, error) {
+	decoded := make([][]byte, len(proof))
+	for i, node := range proof {
+		hash, err := hex.DecodeString(strings.TrimPrefix(node, "0x"))
+		if err != nil || len(hash) != HashLen {
+			return nil, fmt.Errorf("proof item %d is not 32 bytes of hex", i)
+		}
+		decoded[i] = hash
+	}
+	return decoded, nil
+}
+
+func encodeMerkleProof(proof [][]byte) []string {
+	encoded := make([]string, len(proof))
+	for i, node := range proof {
+		encoded[i] = "0x" + hex.EncodeToString(node)
+	}
+	return encoded
+}
+
+func (b *backend) signMerkle(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	items := data.Get("payloads").([]string)
+	if len(items) == 0 {
+		return nil, errors.New("payloads are empty")
+	}
+	if len(items) > MaxMerklePayloads {
+		return nil, fmt.Errorf("too many payloads %d, the maximum is %d", len(items), MaxMerklePayloads)
+	}
+	payloads := make([][]byte, len(items))
+	for i, item := range items {
+		payload, err := decodeMessage(item, data.Get("encoding").(string))
+		if err != nil {
+			return nil, fmt.Errorf("payload %d - %v", i, err)
+		}
+		payloads[i] = payload
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	root, proofs := BuildMerkleTree(payloads)
+	hash := HashMerkleRoot(root, len(payloads))
+	signedRoot, err := NewSignature(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signedRoot.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
+	inclusions := make([]map[string]interface{}, len(payloads))
+	for i, payload := range payloads {
+		inclusions[i] = map[string]interface{}{
+			"leaf_index": i,
+			"leaf_hash":  "0x" + hex.EncodeToString(merkleLeafHash(payload)),
+			"proof":      encodeMerkleProof(proofs[i]),
+		}
+	}
+	b.Logger().Info("[SIGN][OK] Signed a Merkle root", "address", account.Address, "tree_size", len(payloads))
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":   account.Address,
+			"root":      "0x" + hex.EncodeToString(root),
+			"tree_size": len(payloads),
+			"hash":      "0x" + hex.EncodeToString(hash),
+			"signature": signature,
+			"proofs":    inclusions,
+		},
+	}, nil
+}
+
+func (b *backend) verifyMerkle(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	payload, err := decodeMessage(data.Get("payload").(string), data.Get("encoding").(string))
+	if err != nil {
+		return nil, err
+	}
+	root, err := hex.DecodeString(strings.TrimPrefix(data.Get("root").(string), "0x"))
+	if err != nil || len(root) != HashLen {
+		return nil, errors.New("root must be 32 bytes of hex")
+	}
+	proof, err := decodeMerkleProof(data.Get("proof").([]string))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ParseSignatureString(data.Get("signature").(string))
+	if err != nil {
+		return nil, err
+	}
+	index, size := data.Get("leaf_index").(int), data.Get("tree_size").(int)
+	name := data.Get("address").(string)
+	if name == "" {
+		return nil, errors.New("address is required")
+	}
+	address, err := b.resolveAddress(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if address == "" {
+		return nil, fmt.Errorf("Invalid 'address' value=%s, len=%d", name, len(name))
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"root":  "0x" + hex.EncodeToString(root),
+			"valid": false,
+		},
+	}
+	hash := HashMerkleRoot(root, size)
+	publicKey, err := signature.RecoverPublicKey(hash)
+	if err == nil {
+		resp.Data["address"] = publicKey.Address()
+	}
+	switch {
+	case err != nil:
+		resp.Data["reason"] = err.Error()
+	case !signature.Verify(hash, publicKey):
+		resp.Data["reason"] = "invalid signature"
+	case publicKey.Address() != address:
+		resp.Data["reason"] = "signature is not made by " + address
+	case !VerifyMerkleProof(payload, index, size, proof, root):
+		resp.Data["reason"] = "payload is not included in the root"
+	default:
+		resp.Data["valid"] = true
+	}
+	return resp, nil
+}
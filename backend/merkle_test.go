@@ -0,0 +1,132 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleTree(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		payloads := make([][]byte, size)
+		for i := range payloads {
+			payloads[i] = []byte(fmt.Sprintf("attestation-%d", i))
+		}
+		root, proofs := BuildMerkleTree(payloads)
+		for i, payload := range payloads {
+			assert.True(t, VerifyMerkleProof(payload, i, size, proofs[i], root), "size %d index %d", size, i)
+			assert.False(t, VerifyMerkleProof([]byte("forged"), i, size, proofs[i], root))
+			assert.False(t, VerifyMerkleProof(payload, size, size, proofs[i], root))
+			if size > 1 {
+				assert.False(t, VerifyMerkleProof(payload, (i+1)%size, size, proofs[i], root))
+			}
+		}
+	}
+	root, proofs := BuildMerkleTree([][]byte{[]byte("only")})
+	assert.Equal(t, merkleLeafHash([]byte("only")), root)
+	assert.Empty(t, proofs[0])
+
+	// an inner node is not a valid leaf
+	payloads := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	root, _ = BuildMerkleTree(payloads)
+	node := append(merkleLeafHash(payloads[0]), merkleLeafHash(payloads[1])...)
+	assert.False(t, VerifyMerkleProof(node, 0, 2, [][]byte{merkleNodeHash(merkleLeafHash(payloads[2]), merkleLeafHash(payloads[3]))}, root))
+}
+
+func TestSignMerkle(t *testing.T) {
+	b, storage := getBackend(t)
+	call := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Storage = storage
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+	res, err := call("accounts", map[string]interface{}{"name": "merkle-signer"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	payloads := []string{"0x01", "0x0203", "0x", "0xff", "0x7f7f"}
+	resp, err := call("accounts/"+address+"/sign_merkle", map[string]interface{}{
+		"payloads":         payloads,
+		"encoding":         "hex",
+		"signature_format": SignatureFormatHexRSV,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, resp.Data["address"])
+	assert.Equal(t, len(payloads), resp.Data["tree_size"])
+	root := resp.Data["root"].(string)
+	signature := resp.Data["signature"].(string)
+	proofs := resp.Data["proofs"].([]map[string]interface{})
+
+	verifyWithSize := func(i int, payload, signer string, size int) *logical.Response {
+		resp, err := call("merkle/verify", map[string]interface{}{
+			"payload":    payload,
+			"encoding":   "hex",
+			"leaf_index": proofs[i]["leaf_index"],
+			"tree_size":  size,
+			"proof":      proofs[i]["proof"],
+			"root":       root,
+			"signature":  signature,
+			"address":    signer,
+		})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	verify := func(i int, payload, signer string) *logical.Response {
+		return verifyWithSize(i, payload, signer, len(payloads))
+	}
+	for i, payload := range payloads {
+		resp := verify(i, payload, address)
+		assert.Equal(t, true, resp.Data["valid"], resp.Data["reason"])
+		assert.Equal(t, address, resp.Data["address"])
+	}
+	// the expected signer can be given by its alias
+	resp = verify(2, payloads[2], "merkle-signer")
+	assert.Equal(t, true, resp.Data["valid"], resp.Data["reason"])
+	resp = verify(1, "0x0204", address)
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, "payload is not included in the root", resp.Data["reason"])
+
+	// the tree size is signed with the root, as a proof does not bind it
+	resp = verifyWithSize(0, payloads[0], address, len(payloads)+1)
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, "signature is not made by "+address, resp.Data["reason"])
+
+	other, _ := GenerateKey()
+	resp = verify(0, payloads[0], other.PublicKey().Address())
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, "signature is not made by "+other.PublicKey().Address(), resp.Data["reason"])
+
+	_, err = call("merkle/verify", map[string]interface{}{"root": root, "signature": signature})
+	assert.EqualError(t, err, "address is required")
+	_, err = call("merkle/verify", map[string]interface{}{"root": root, "signature": signature, "address": "unknown"})
+	assert.EqualError(t, err, "Invalid 'address' value=unknown, len=7")
+
+	_, err = call("accounts/"+address+"/sign_merkle", map[string]interface{}{"payloads": []string{}})
+	assert.EqualError(t, err, "payloads are empty")
+	_, err = call("accounts/"+address+"/sign_merkle", map[string]interface{}{"payloads": make([]string, MaxMerklePayloads+1)})
+	assert.EqualError(t, err, fmt.Sprintf("too many payloads %d, the maximum is %d", MaxMerklePayloads+1, MaxMerklePayloads))
+}
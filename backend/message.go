@@ -0,0 +1,124 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// MessagePrefix is the header of an off-chain message signed by sign_message.
+// The signed hash is SHA3-256(MessagePrefix + decimal length + message). As
+// transactions are serialized after "icx_sendTransaction.", a signed message
+// can never be a valid transaction.
+const MessagePrefix = "\x19ICON Signed Message:\n"
+
+// HashMessage returns the hash signed for an off-chain message.
+func HashMessage(message []byte) []byte {
+	prefixed := make([]byte, 0, len(MessagePrefix)+20+len(message))
+	prefixed = append(prefixed, MessagePrefix...)
+	prefixed = strconv.AppendInt(prefixed, int64(len(message)), 10)
+	prefixed = append(prefixed, message...)
+	return SHA3Sum256(prefixed)
+}
+
+// VerifyMessage returns whether the signature of the message was made by the
+// account of the address.
+func VerifyMessage(message []byte, sig *Signature, address string) bool {
+	hash := HashMessage(message)
+	publicKey, err := sig.RecoverPublicKey(hash)
+	if err != nil {
+		return false
+	}
+	return sig.Verify(hash, publicKey) && publicKey.Address() == address
+}
+
+// decodeMessage decodes a message given as utf8 text or hex.
+func decodeMessage(message, encoding string) ([]byte, error) {
+	switch encoding {
+	case "", "utf8":
+		return []byte(message), nil
+	case "hex":
+		decoded, err := hex.DecodeString(strings.TrimPrefix(message, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("message is not a hex string - %v", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported message encoding - %s", encoding)
+	}
+}
+
+// retrieveSigningKey returns the account and the private key of a secp256k1
+// account that passes the signing policies.
+func (b *backend) retrieveSigningKey(ctx context.Context, req *logical.Request, name string, policies []signingPolicy) (*Account, *PrivateKey, error) {
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account == nil {
+		return nil, nil, fmt.Errorf("Signing account %s does not exist", name)
+	}
+	if err := checkSigningPolicies(policies, account); err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := ParsePrivateKeyFromString(account.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, privateKey, nil
+}
+
+func (b *backend) signMessage(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	message, err := decodeMessage(data.Get("message").(string), data.Get("encoding").(string))
+	if err != nil {
+		return nil, err
+	}
+	if len(message) == 0 {
+		return nil, fmt.Errorf("message is empty")
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := HashMessage(message)
+	signedMessage, err := NewSignature(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signedMessage.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[SIGN][OK] Signed a message", "address", account.Address)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":   account.Address,
+			"hash":      "0x" + hex.EncodeToString(hash),
+			"signature": signature,
+		},
+	}, nil
+}
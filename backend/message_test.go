@@ -0,0 +1,101 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashMessage(t *testing.T) {
+	expected := SHA3Sum256([]byte("\x19ICON Signed Message:\n5hello"))
+	assert.Equal(t, expected, HashMessage([]byte("hello")))
+	assert.NotEqual(t, SHA3Sum256([]byte("hello")), HashMessage([]byte("hello")))
+}
+
+func TestSignMessage(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "dapp"}
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/dapp/sign_message")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"message":          "0x" + hex.EncodeToString([]byte("I own this address")),
+		"encoding":         "hex",
+		"signature_format": SignatureFormatHexRSV,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, resp.Data["address"])
+	assert.Equal(t, "0x"+hex.EncodeToString(HashMessage([]byte("I own this address"))), resp.Data["hash"])
+	sig, err := ParseSignatureString(resp.Data["signature"].(string))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.True(t, VerifyMessage([]byte("I own this address"), sig, address))
+	assert.False(t, VerifyMessage([]byte("I own that address"), sig, address))
+
+	resp, err = verify(t, b, storage, map[string]interface{}{
+		"signed_message": "I own this address",
+		"signature":      sig.String(),
+		"address":        "dapp",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, true, resp.Data["matches"])
+	assert.Equal(t, true, resp.Data["valid"])
+}
+
+func TestSignMessageFailure(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	signMessage := func(data map[string]interface{}) error {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/sign_message")
+		req.Storage = storage
+		req.Data = data
+		_, err := b.HandleRequest(context.Background(), req)
+		return err
+	}
+	assert.EqualError(t, signMessage(map[string]interface{}{}), "message is empty")
+	assert.EqualError(t, signMessage(map[string]interface{}{"message": "a", "encoding": "base32"}), "unsupported message encoding - base32")
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/disable")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.EqualError(t, signMessage(map[string]interface{}{"message": "a"}), "Signing account "+address+" is disabled")
+}
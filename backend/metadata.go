@@ -0,0 +1,104 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// metadataFields are the mutable descriptive fields of an account
+var metadataFields = []string{"description", "owner_team", "purpose", "cost_center", "labels"}
+
+// accountMetadata returns the descriptive fields of the account.
+func accountMetadata(account *Account) map[string]interface{} {
+	labels := account.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return map[string]interface{}{
+		"description": account.Description,
+		"owner_team":  account.OwnerTeam,
+		"purpose":     account.Purpose,
+		"cost_center": account.CostCenter,
+		"labels":      labels,
+		"updated_at":  account.UpdatedAt,
+		"updated_by":  account.UpdatedBy,
+	}
+}
+
+// callerIdentity returns the entity ID of the caller, or the display name
+// for tokens without an entity.
+func callerIdentity(req *logical.Request) string {
+	if req.EntityID != "" {
+		return req.EntityID
+	}
+	return req.DisplayName
+}
+
+func (b *backend) updateAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("[UPDATE][FAIL] Account does not exist - %s", name)
+	}
+
+	var changed []string
+	for _, field := range metadataFields {
+		value, ok := data.GetOk(field)
+		if !ok {
+			continue
+		}
+		switch field {
+		case "description":
+			account.Description = value.(string)
+		case "owner_team":
+			account.OwnerTeam = value.(string)
+		case "purpose":
+			account.Purpose = value.(string)
+		case "cost_center":
+			account.CostCenter = value.(string)
+		case "labels":
+			account.Labels = value.(map[string]string)
+		}
+		changed = append(changed, field)
+	}
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("[UPDATE][FAIL] No metadata field to update")
+	}
+	sort.Strings(changed)
+
+	account.UpdatedAt = time.Now().Unix()
+	account.UpdatedBy = callerIdentity(req)
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "update_metadata", account.Address, map[string]interface{}{
+		"fields": changed,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[UPDATE][OK] Updated account metadata", "address", account.Address, "fields", changed, "updated_by", account.UpdatedBy)
+
+	return b.readAccount(ctx, req, data)
+}
@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAccountMetadata(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name": "payroll",
+	}
+	resp, _ := b.HandleRequest(context.Background(), req)
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/payroll")
+	req.Storage = storage
+	req.EntityID = "entity-1"
+	req.Data = map[string]interface{}{
+		"description": "Monthly payroll",
+		"owner_team":  "finance",
+		"labels":      map[string]interface{}{"env": "prod", "tier": "hot"},
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("Monthly payroll", resp.Data["description"])
+	assert.Equal("entity-1", resp.Data["updated_by"])
+	assert.NotEqual(int64(0), resp.Data["updated_at"])
+
+	// fields that are not given are kept
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address)
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"cost_center": "CC-1024",
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address)
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal("finance", resp.Data["owner_team"])
+	assert.Equal("CC-1024", resp.Data["cost_center"])
+	assert.Equal(map[string]string{"env": "prod", "tier": "hot"}, resp.Data["labels"])
+
+	req = logical.TestRequest(t, logical.ListOperation, "accounts")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	info := resp.Data["key_info"].(map[string]interface{})[address].(map[string]interface{})
+	assert.Equal("payroll", info["alias_name"])
+	assert.Equal("finance", info["owner_team"])
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address)
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("[UPDATE][FAIL] No metadata field to update", err.Error())
+}
@@ -0,0 +1,57 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathAuditList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "audit/?",
+		HelpSynopsis: "List the audit entries recorded by the plugin.",
+		HelpDescription: `
+
+    LIST - list the audit entries in chronological order
+
+    `,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.listAuditEntries,
+			},
+		},
+	}
+}
+
+func pathAuditRead(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "audit/" + framework.GenericNameRegex("id"),
+		HelpSynopsis: "Get an audit entry recorded by the plugin.",
+		HelpDescription: `
+
+    GET - return the audit entry by the id
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"id": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readAuditEntry,
+			},
+		},
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// authChallengeFields are the fields that make the challenge of sign_auth and verify_auth.
+func authChallengeFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"walletAddress": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "walletAddress, It is forcibly converted to the registered account name.",
+		},
+		"time": &framework.FieldSchema{
+			Type:        framework.TypeInt,
+			Description: "Timestamp seconds (INT), within the auth_time_window of now",
+			Default:     0,
+		},
+		"template": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) Name of the auth template. The default challenge is {address}{time}.",
+			Default:     "",
+		},
+		"nonce": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) Value of the {nonce} placeholder, 8 to 64 alphanumeric characters",
+			Default:     "",
+		},
+		"domain": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) Value of the {domain} placeholder, a host name with an optional port",
+			Default:     "",
+		},
+	}
+}
+
+func pathVerifyAuth(b *backend) *framework.Path {
+	fields := authChallengeFields()
+	fields["signature"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Base64 or 0x-prefixed hex of the [R|S|V] signature returned by sign_auth",
+		Default:     "",
+	}
+	return &framework.Path{
+		Pattern:      "verify_auth",
+		HelpSynopsis: "Verify a signed auth challenge.",
+		HelpDescription: `
+
+    Rebuild the challenge signed by sign_auth and check that it is fresh and signed by the wallet address.
+
+    `,
+		Fields: fields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.verifyAuth,
+			},
+		},
+	}
+}
+
+func pathAuthTemplateList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "auth_templates/?",
+		HelpSynopsis: "List the auth challenge templates.",
+		HelpDescription: `
+
+    LIST - list the names of the auth templates
+
+    `,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.listAuthTemplates,
+			},
+		},
+	}
+}
+
+func pathAuthTemplate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "auth_templates/" + framework.GenericNameRegex("name"),
+		HelpSynopsis: "Manage an auth challenge template.",
+		HelpDescription: `
+
+    GET - return the auth template
+    POST - create or replace the auth template
+    DELETE - delete the auth template
+
+    The format is the challenge text with the placeholders {address}, {time}, {nonce} and {domain}.
+    {address} and {time} are required.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"format": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Challenge format, for example \"{domain} wants you to sign in with {address} at {time}, nonce {nonce}\"",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readAuthTemplate,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.writeAuthTemplate,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.deleteAuthTemplate,
+			},
+		},
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+var backupFields = map[string]*framework.FieldSchema{
+	"name": &framework.FieldSchema{Type: framework.TypeString},
+	"recipients": &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: "Custodian public keys, one per share. Each is an age recipient (age1...) or a PGP public key, armored or base64 encoded.",
+	},
+	"threshold": &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "Number of shares required to recover the key",
+		Default:     2,
+	},
+}
+
+func pathBackup(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/backup",
+		HelpSynopsis: "Split the key of an exportable ICON account into Shamir shares.",
+		HelpDescription: `
+
+    Split the private key into one share per recipient, any threshold of which recover it.
+    Each share is hex encoded and encrypted to its recipient, so no plaintext share is returned.
+
+    `,
+		Fields: backupFields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.backupAccount,
+			},
+		},
+	}
+}
+
+func pathHDWalletBackup(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "hd/" + framework.GenericNameRegex("name") + "/backup",
+		HelpSynopsis: "Split the seed of an HD wallet into Shamir shares.",
+		HelpDescription: `
+
+    Split the seed into one share per recipient, any threshold of which recover it.
+    Each share is hex encoded and encrypted to its recipient, so no plaintext share is returned.
+    The returned address is the first account of the wallet, needed to check the recovery.
+    All the accounts derived from the wallet must be exportable, and once backed up
+    the wallet only derives exportable accounts.
+
+    `,
+		Fields: backupFields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.backupHDWallet,
+			},
+		},
+	}
+}
+
+func pathRecoverAccount(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "recover/account",
+		HelpSynopsis: "Recover an ICON account from Shamir shares.",
+		HelpDescription: `
+
+    Rebuild the private key from the decrypted shares, check it against the address and store the account.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"shares": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Decrypted hex encoded shares, at least the threshold",
+			},
+			"address": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Address of the account the shares were split from",
+			},
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Alias name of the recovered account",
+				Default:     "",
+			},
+			"exportable": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the private key to be exported. It cannot be changed after creation.",
+				Default:     false,
+			},
+			"overwrite": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Replace an existing account with the same address or alias",
+				Default:     false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.recoverAccount,
+			},
+		},
+	}
+}
+
+func pathRecoverHDWallet(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "recover/hd",
+		HelpSynopsis: "Recover an HD wallet from Shamir shares.",
+		HelpDescription: `
+
+    Rebuild the seed from the decrypted shares, check the first account against the address and store the HD wallet.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"shares": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Decrypted hex encoded shares, at least the threshold",
+			},
+			"address": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Address of the first account of the wallet, returned by the backup",
+			},
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the recovered HD wallet",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.recoverHDWallet,
+			},
+		},
+	}
+}
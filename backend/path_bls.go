@@ -0,0 +1,66 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathBLSSign(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/bls/sign",
+		HelpSynopsis: "Sign a message with a BLS12-381 key.",
+		HelpDescription: `
+
+    Sign the message with the BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_ ciphersuite.
+    The signature is a 96-byte compressed G2 point.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"message": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex encoded message to sign",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signBLS,
+			},
+		},
+	}
+}
+
+func pathBLSProofOfPossession(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/bls/pop",
+		HelpSynopsis: "Generate the proof of possession of a BLS12-381 key.",
+		HelpDescription: `
+
+    Sign the public key with the BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_ ciphersuite,
+    as required to register the public key.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.proveBLSPossession,
+			},
+		},
+	}
+}
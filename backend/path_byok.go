@@ -0,0 +1,85 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathWrappingKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "wrapping_key",
+		HelpSynopsis: "Return the public key used to wrap imported keys.",
+		HelpDescription: `
+
+    GET - return the RSA-4096 wrapping public key in PEM format.
+          The key is generated on the first request.
+
+    `,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readWrappingKey,
+			},
+		},
+	}
+}
+
+func pathImportWrapped(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "import/wrapped",
+		HelpSynopsis: "Import an ICON account from a wrapped private key",
+		HelpDescription: `
+
+    POST - unwrap a private key and store the account.
+
+    The ciphertext is the RSA-OAEP encrypted ephemeral AES-256 key, followed by
+    the private key wrapped with AES-KWP (RFC 5649) under the ephemeral key.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"ciphertext": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 encoded wrapped private key",
+				Default:     "",
+			},
+			"hash_function": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hash function used for RSA-OAEP (SHA1, SHA224, SHA256, SHA384, SHA512)",
+				Default:     "SHA256",
+			},
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Alias address of the wallet",
+				Default:     "",
+			},
+			"exportable": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the private key to be exported. It cannot be changed after creation.",
+				Default:     false,
+			},
+			"overwrite": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Replace an existing account with the same address or alias",
+				Default:     false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.importWrappedKey,
+			},
+		},
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathCSR(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/csr",
+		HelpSynopsis: "Create an X.509 certificate request signed by an ICON account.",
+		HelpDescription: `
+
+    Create a PEM PKCS#10 request for the secp256k1 key of the account, signed with ecdsa-with-SHA256.
+    The common name defaults to the account address.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"common_name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Common name of the subject. Defaults to the account address.",
+			},
+			"organization": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "O of the subject",
+			},
+			"organizational_unit": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "OU of the subject",
+			},
+			"country": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "C of the subject",
+			},
+			"province": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "ST of the subject",
+			},
+			"locality": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "L of the subject",
+			},
+			"dns_names": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "DNS subject alternative names",
+			},
+			"email_addresses": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Email subject alternative names",
+			},
+			"ip_sans": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "IP subject alternative names",
+			},
+			"uri_sans": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "URI subject alternative names, such as a did:icon DID",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.createCSR,
+			},
+		},
+	}
+}
+
+func pathSignCertificate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_certificate",
+		HelpSynopsis: "Issue an X.509 certificate with an ICON account as the CA.",
+		HelpDescription: `
+
+    Issue a TLS client and server certificate for a PKCS#10 request, signed with the secp256k1 key of the account.
+    The subject and the subject alternative names are copied from the request and must be allowed by the role.
+    The issuer is CN=<address>. issuing_ca is the self-signed CA certificate of the account, created on the
+    first issuance with a validity of 10 years. The ttl is bounded by certificate_max_ttl.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"csr": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "PEM certificate request",
+				Default:     "",
+			},
+			"role": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Certificate role allowing the subject and the subject alternative names",
+				Default:     "",
+			},
+			"ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the certificate. Defaults to certificate_max_ttl.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signCertificate,
+			},
+		},
+	}
+}
+
+func pathCertificateRoleList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "certificate_roles/?",
+		HelpSynopsis: "List the certificate roles.",
+		HelpDescription: `
+
+    LIST - list the names of the certificate roles
+
+    `,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.listCertificateRoles,
+			},
+		},
+	}
+}
+
+func pathCertificateRole(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "certificate_roles/" + framework.GenericNameRegex("name"),
+		HelpSynopsis: "Manage a certificate role.",
+		HelpDescription: `
+
+    GET - return the certificate role
+    POST - create or replace the certificate role
+    DELETE - delete the certificate role
+
+    A role lists the names allowed in the certificates issued with it. The common name, the DNS
+    names and the domains of the email addresses must be one of allowed_domains, or a subdomain
+    of one if allow_subdomains is set. A wildcard name such as *.example.com also needs
+    allow_wildcard_certificates. The subject may only hold the common name and the listed
+    O, OU, C, ST and L values. Names and attributes that are not listed are refused.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"allowed_domains": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Names allowed as the common name, DNS names and email domains",
+			},
+			"allow_subdomains": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the subdomains of allowed_domains",
+				Default:     false,
+			},
+			"allow_wildcard_certificates": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow wildcard names of the allowed subdomains, such as *.example.com",
+				Default:     false,
+			},
+			"allow_ip_sans": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow IP subject alternative names",
+				Default:     false,
+			},
+			"allowed_uri_sans": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "URIs allowed as subject alternative names, a trailing * matches any suffix",
+			},
+			"allowed_organizations": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "O values allowed in the subject",
+			},
+			"allowed_organizational_units": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "OU values allowed in the subject",
+			},
+			"allowed_countries": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "C values allowed in the subject",
+			},
+			"allowed_provinces": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "ST values allowed in the subject",
+			},
+			"allowed_localities": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "L values allowed in the subject",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readCertificateRole,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.writeCertificateRole,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.deleteCertificateRole,
+			},
+		},
+	}
+}
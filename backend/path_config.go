@@ -0,0 +1,67 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "config",
+		HelpSynopsis: "Configure the plugin backend.",
+		HelpDescription: `
+
+    GET - return the plugin configuration
+    POST - update the plugin configuration
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"max_key_age": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum age of an account key before it is due for rotation. 0 disables the check.",
+			},
+			"deletion_grace_period": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Time a deleted account can be restored before it is purged. 0 deletes immediately.",
+			},
+			"auth_time_window": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum distance between the time of an auth challenge and now. Defaults to 5 minutes.",
+			},
+			"jwt_max_ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum lifetime of an issued JWT. Defaults to 1 hour.",
+			},
+			"jwt_audiences": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Audiences an issued JWT can have. Required before a JWT can be issued.",
+			},
+			"certificate_max_ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Maximum lifetime of a certificate issued by an account. Defaults to 30 days.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readConfig,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.writeConfig,
+			},
+		},
+	}
+}
@@ -34,7 +34,7 @@ func pathCreateAndList(b *backend) *framework.Path {
 		HelpSynopsis: "List all the ICON accounts maintained by the plugin backend and create new accounts.",
 		HelpDescription: `
 
-    LIST - list all accounts
+    LIST - list the accounts, optionally filtered and paginated
     POST - create a new account
 
     `,
@@ -49,11 +49,60 @@ func pathCreateAndList(b *backend) *framework.Path {
 				Description: "Alias ​​address of the wallet",
 				Default:     "",
 			},
+			"key_type": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Type of the key, secp256k1 for wallets or bls12-381 for P-Rep node keys",
+				Default:     KeyTypeSECP256K1,
+			},
+			"exportable": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the private key to be exported. It cannot be changed after creation.",
+				Default:     false,
+			},
+			"overwrite": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Replace an existing account with the same address or alias",
+				Default:     false,
+			},
 			"detail": &framework.FieldSchema{
 				Type:        framework.TypeBool,
 				Description: "Choose the detail options (true/false)",
 				Default:     true,
 			},
+			"after": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(list) Return the accounts whose address sorts after this address, the next of the previous page",
+				Default:     "",
+			},
+			"limit": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "(list) Maximum number of accounts to read for the page. 0 reads 100. The response holds next while more accounts are left.",
+				Default:     0,
+			},
+			"alias_prefix": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(list) Return the accounts whose alias starts with this prefix",
+				Default:     "",
+			},
+			"labels": &framework.FieldSchema{
+				Type:        framework.TypeKVPairs,
+				Description: "(list) Return the accounts having all of these labels",
+			},
+			"status": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(list) Return the accounts in this status",
+				Default:     "",
+			},
+			"created_after": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "(list) Return the accounts created at or after this unix time",
+				Default:     0,
+			},
+			"created_before": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "(list) Return the accounts created before this unix time",
+				Default:     0,
+			},
 		},
 	}
 }
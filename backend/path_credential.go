@@ -0,0 +1,72 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathSignCredential(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/credential",
+		HelpSynopsis: "Sign a W3C verifiable credential.",
+		HelpDescription: `
+
+    Add a JcsEcdsaSecp256k1Signature2019 proof to a credential. The signing input is the JCS
+    (RFC 8785) form of the proof options and of the credential, and the proof is a detached ES256K JWS.
+    The credential is signed as JSON, it is not expanded or normalized as JSON-LD.
+    The issuer is the did:icon DID of the account.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"credential": &framework.FieldSchema{
+				Type:        framework.TypeMap,
+				Description: "JSON-LD credential without a proof",
+			},
+			"proof_purpose": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Purpose of the proof",
+				Default:     "assertionMethod",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signCredential,
+			},
+		},
+	}
+}
+
+func pathDIDDocument(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/did",
+		HelpSynopsis: "Return the DID document of an ICON account.",
+		HelpDescription: `
+
+    Return the did:icon DID document of the account, derived from its public key.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readDIDDocument,
+			},
+		},
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathEncrypt(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "encrypt",
+		HelpSynopsis: "Encrypt data to an ICON account with ECIES.",
+		HelpDescription: `
+
+    Encrypt data to a secp256k1 public key with ECIES and AES-256-GCM, in the eciesjs format.
+    It only uses the public key and helps to test the decryption of an account.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Address or alias of a managed account to encrypt to",
+				Default:     "",
+			},
+			"public_key": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex of the public key to encrypt to, compressed or uncompressed",
+				Default:     "",
+			},
+			"plaintext": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 of the data to encrypt",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.encryptECIES,
+			},
+		},
+	}
+}
+
+func pathDecrypt(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/decrypt",
+		HelpSynopsis: "Decrypt an ECIES ciphertext with an ICON account.",
+		HelpDescription: `
+
+    Decrypt a ciphertext encrypted to the account's public key. The account must allow key_agreement.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"ciphertext": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 of the ECIES ciphertext",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.decryptECIES,
+			},
+		},
+	}
+}
+
+func pathECDH(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/ecdh",
+		HelpSynopsis: "Derive an ECDH shared secret with an ICON account.",
+		HelpDescription: `
+
+    Return the x coordinate of the ECDH shared point of the account key and a peer public key.
+    The account must allow key_agreement.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"public_key": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex of the peer public key, compressed or uncompressed",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.deriveECDH,
+			},
+		},
+	}
+}
+
+func pathEnableKeyAgreement(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/key_agreement/enable",
+		HelpSynopsis: "Allow an ICON account to decrypt and derive ECDH secrets.",
+		HelpDescription: `
+
+    Allow the account key to be used by decrypt and ecdh.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.enableKeyAgreement,
+			},
+		},
+	}
+}
+
+func pathDisableKeyAgreement(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/key_agreement/disable",
+		HelpSynopsis: "Forbid an ICON account to decrypt and derive ECDH secrets.",
+		HelpDescription: `
+
+    Forbid the account key to be used by decrypt and ecdh.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.disableKeyAgreement,
+			},
+		},
+	}
+}
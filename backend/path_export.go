@@ -25,17 +25,73 @@ func pathExport(b *backend) *framework.Path {
 		HelpSynopsis: "Export an ICON account",
 		HelpDescription: `
 
-    GET - return the account by the name
+    POST - return the private key of an exportable account, or a keystore v3
+           JSON encrypted with the passphrase if one is given.
+
+    The request must be response-wrapped, and every export is audited.
 
     `,
 		Fields: map[string]*framework.FieldSchema{
 			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"passphrase": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Passphrase to encrypt the exported keystore",
+				Default:     "",
+			},
+			"kdf": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Key derivation function of the keystore (scrypt or pbkdf2)",
+				Default:     KeyStoreKDFScrypt,
+			},
 		},
-		ExistenceCheck: b.pathExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
-			logical.ReadOperation: &framework.PathOperation{
+			logical.UpdateOperation: &framework.PathOperation{
 				Callback: b.exportAccount,
 			},
 		},
 	}
 }
+
+func pathImportKeyStore(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "import/keystore",
+		HelpSynopsis: "Import an ICON account from a keystore",
+		HelpDescription: `
+
+    POST - decrypt a keystore v3 JSON (scrypt or pbkdf2) and store the account
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"keystore": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Keystore v3 JSON",
+				Default:     "",
+			},
+			"passphrase": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Passphrase of the keystore",
+				Default:     "",
+			},
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Alias address of the wallet",
+				Default:     "",
+			},
+			"exportable": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the private key to be exported. It cannot be changed after creation.",
+				Default:     false,
+			},
+			"overwrite": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Replace an existing account with the same address or alias",
+				Default:     false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.importKeyStore,
+			},
+		},
+	}
+}
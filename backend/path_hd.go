@@ -0,0 +1,137 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathHDWalletCreateAndList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "hd/?",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.listHDWallets,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.createHDWallet,
+			},
+		},
+
+		HelpSynopsis: "List the HD wallets maintained by the plugin backend and create new HD wallets.",
+		HelpDescription: `
+
+    LIST - list all HD wallets
+    POST - generate a new BIP-39 mnemonic, or import an existing one
+
+    The mnemonic is returned only once, when it is generated by the plugin, and
+    generating one requires response wrapping. As the mnemonic of every wallet
+    exists outside the plugin, only exportable accounts can be derived from it.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the HD wallet",
+				Default:     "",
+			},
+			"mnemonic": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) BIP-39 mnemonic to import. If empty, a new mnemonic is generated.",
+				Default:     "",
+			},
+			"passphrase": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) BIP-39 passphrase used to derive the seed",
+				Default:     "",
+			},
+			"strength": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "Entropy bits of a generated mnemonic (128 or 256)",
+				Default:     256,
+			},
+		},
+	}
+}
+
+func pathHDWalletReadAndDelete(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "hd/" + framework.GenericNameRegex("name"),
+		HelpSynopsis: "Get or delete an HD wallet by name",
+		HelpDescription: `
+
+    GET - return the HD wallet by the name
+    DELETE - deletes the HD wallet by the name. Derived accounts are kept.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readHDWallet,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.deleteHDWallet,
+			},
+		},
+	}
+}
+
+func pathHDWalletDerive(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "hd/" + framework.GenericNameRegex("name") + "/derive",
+		HelpSynopsis: "Derive an ICON account from an HD wallet.",
+		HelpDescription: `
+
+    Derive the account at m/44'/74'/account'/0/index and store it as a regular account.
+    If index is omitted, the next unused index of the wallet is used.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"account": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "BIP-44 account (hardened)",
+				Default:     0,
+			},
+			"index": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "(optional) BIP-44 address index",
+			},
+			"alias_name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Alias address of the derived wallet",
+				Default:     "",
+			},
+			"exportable": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Allow the private key to be exported. It cannot be changed after creation. Required once the seed exists outside the plugin.",
+				Default:     false,
+			},
+			"overwrite": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Replace an existing account with the same address or alias",
+				Default:     false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.deriveHDAccount,
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathJWT(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/jwt",
+		HelpSynopsis: "Issue an ES256K JWT signed by an ICON account.",
+		HelpDescription: `
+
+    Issue a compact JWS with the ES256K algorithm. iss is the account address and the kid of the JWKS.
+    iat and exp are set by the plugin, the ttl is bounded by jwt_max_ttl.
+    Every token names at least one audience, which must be listed in jwt_audiences.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"claims": &framework.FieldSchema{
+				Type:        framework.TypeMap,
+				Description: "Claims of the token, such as sub. iss, aud, iat, nbf and exp are reserved.",
+			},
+			"audience": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Audiences of the token, at least one",
+			},
+			"ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Lifetime of the token. Defaults to jwt_max_ttl.",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.issueJWT,
+			},
+		},
+	}
+}
+
+func pathJWKS(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/jwks",
+		HelpSynopsis: "Return the JWKS of an ICON account.",
+		HelpDescription: `
+
+    Return the JSON Web Key Set to verify the tokens issued by the account.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.readJWKS,
+			},
+		},
+	}
+}
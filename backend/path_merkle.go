@@ -0,0 +1,108 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathSignMerkle(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_merkle",
+		HelpSynopsis: "Sign a batch of payloads with one signature over their Merkle root.",
+		HelpDescription: `
+
+    Build a SHA3-256 Merkle tree of the payloads and sign the hash of "\x19ICON Merkle Root:\n", the 8-byte tree size and the root.
+    Leaves are the hash of 0x00 and the payload and nodes the hash of 0x01 and their children, and an odd node is promoted.
+    Each payload gets an inclusion proof that merkle/verify checks against the signed root.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"payloads": &framework.FieldSchema{
+				Type:        framework.TypeStringSlice,
+				Description: "Payloads to sign, at most 10000",
+			},
+			"encoding": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Encoding of the payloads, utf8 or hex",
+				Default:     "utf8",
+			},
+			"signature_format": signatureFormatField(),
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signMerkle,
+			},
+		},
+	}
+}
+
+func pathMerkleVerify(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "merkle/verify",
+		HelpSynopsis: "Verify a payload of a signed Merkle batch.",
+		HelpDescription: `
+
+    Check the inclusion proof of a payload against the root, and that the root is signed by the address.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"payload": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Payload to verify",
+				Default:     "",
+			},
+			"encoding": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Encoding of the payload, utf8 or hex",
+				Default:     "utf8",
+			},
+			"leaf_index": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "Index of the payload in the batch",
+			},
+			"tree_size": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "Number of payloads in the batch",
+			},
+			"proof": &framework.FieldSchema{
+				Type:        framework.TypeStringSlice,
+				Description: "Hex hashes of the inclusion proof, from the leaf up",
+			},
+			"root": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex of the Merkle root",
+				Default:     "",
+			},
+			"signature": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 or 0x-prefixed hex of the [R|S|V] signature of the root",
+				Default:     "",
+			},
+			"address": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Address or alias of the expected signer of the root",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.verifyMerkle,
+			},
+		},
+	}
+}
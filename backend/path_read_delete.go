@@ -22,22 +22,46 @@ import (
 func pathReadAndDelete(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern:      "accounts/" + framework.GenericNameRegex("name"),
-		HelpSynopsis: "Create, get or delete an ICON account by name",
+		HelpSynopsis: "Get, update or delete an ICON account by name",
 		HelpDescription: `
 
-    POST - create a new account for the given name
     GET - return the account by the name
+    POST - update the metadata of the account by the name
     DELETE - deletes the account by the name
 
+    The name is either the address or the alias of the account.
+
     `,
 		Fields: map[string]*framework.FieldSchema{
 			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"description": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Description of the account",
+			},
+			"owner_team": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Team owning the account",
+			},
+			"purpose": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Purpose of the account",
+			},
+			"cost_center": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Cost center of the account",
+			},
+			"labels": &framework.FieldSchema{
+				Type:        framework.TypeKVPairs,
+				Description: "Free-form key/value labels. Replaces the existing labels.",
+			},
 		},
-		ExistenceCheck: b.pathExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
 				Callback: b.readAccount,
 			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.updateAccount,
+			},
 			logical.DeleteOperation: &framework.PathOperation{
 				Callback: b.deleteAccount,
 			},
@@ -0,0 +1,130 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathRotate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/rotate",
+		HelpSynopsis: "Rotate the key of an ICON account.",
+		HelpDescription: `
+
+    Generate a successor account and retire the current one so it can no longer sign.
+    A transfer of the balance minus the fee (stepLimit * stepPrice) to the successor is
+    signed with the retired key and returned for broadcasting. A zero balance retires the
+    account without a transfer, which requires force.
+    The successor of an account derived from an HD wallet is derived from the same wallet.
+
+    `,
+		Fields: rotationTransferFields(map[string]*framework.FieldSchema{
+			"force": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "(optional) Retire the account without a transfer when the balance is zero.",
+				Default:     false,
+			},
+			"alias_name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Alias of the successor account. Defaults to the alias of the rotated account.",
+				Default:     "",
+			},
+		}),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.rotateAccount,
+			},
+		},
+	}
+}
+
+func pathRotateTransfer(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/rotate/transfer",
+		HelpSynopsis: "Sign the transfer of a rotated account to its successor again.",
+		HelpDescription: `
+
+    Sign a new transfer of the balance minus the fee (stepLimit * stepPrice) from a retired
+    account to its successor, for when the transfer returned by the rotation was dropped or
+    failed. The retired key cannot sign a transfer to any other address.
+
+    `,
+		Fields: rotationTransferFields(map[string]*framework.FieldSchema{}),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.resignRotationTransfer,
+			},
+		},
+	}
+}
+
+// rotationTransferFields adds the fields of the transfer to the successor to fields.
+func rotationTransferFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
+	for name, schema := range map[string]*framework.FieldSchema{
+		"name": &framework.FieldSchema{Type: framework.TypeString},
+		"balance": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Current ICX balance of the account in loop (HEX or decimal)",
+			Required:    true,
+		},
+		"stepLimit": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "The stepLimit of the transfer transaction.",
+			Default:     "0x186a0",
+		},
+		"stepPrice": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "The step price used to calculate the fee.",
+			Default:     DefaultStepPrice,
+		},
+		"nid": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) Network ID of the target blockchain network",
+			Default:     "0x1",
+		},
+		"nonce": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) The transaction nonce.",
+			Default:     "",
+		},
+		"timestamp": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "(optional) Timestamp of the transfer. Defaults to now.",
+			Default:     "",
+		},
+	} {
+		fields[name] = schema
+	}
+	return fields
+}
+
+func pathRotationDue(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "rotation/due/?",
+		HelpSynopsis: "List the accounts due for rotation.",
+		HelpDescription: `
+
+    LIST - list the active accounts older than the configured max_key_age
+
+    `,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.listRotationDue,
+			},
+		},
+	}
+}
@@ -19,6 +19,16 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
+// signatureFormatField is the output encoding of the secp256k1 signature
+// returned by the sign endpoints.
+func signatureFormatField() *framework.FieldSchema {
+	return &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Encoding of the returned signature: base64-rsv, hex-rsv, hex-vrs, compact-rs or split",
+		Default:     SignatureFormatBase64RSV,
+	}
+}
+
 func pathSign(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign",
@@ -66,6 +76,12 @@ func pathSign(b *backend) *framework.Path {
 				Description: "(optional) params of the target blockchain network. ",
 				Default:     "",
 			},
+			"signature_format": signatureFormatField(),
+			"dry_run": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Validate and serialize the transaction and evaluate the signing policies without signing",
+				Default:     false,
+			},
 		},
 		ExistenceCheck: b.pathExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -127,6 +143,12 @@ func pathParamSign(b *backend) *framework.Path {
 				Description: "Timestamp",
 				Default:     TimeStampNow(),
 			},
+			"signature_format": signatureFormatField(),
+			"dry_run": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: "Validate and serialize the transaction and evaluate the signing policies without signing",
+				Default:     false,
+			},
 		},
 		ExistenceCheck: b.pathExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -138,29 +160,54 @@ func pathParamSign(b *backend) *framework.Path {
 }
 
 func pathSignAuth(b *backend) *framework.Path {
+	fields := authChallengeFields()
+	fields["signature_format"] = signatureFormatField()
 	return &framework.Path{
 		Pattern:      "accounts/" + framework.GenericNameRegex("walletAddress") + "/sign_auth",
 		HelpSynopsis: "Sign a provided transaction object.",
 		HelpDescription: `
 
     Sign a transaction object with getting Planet manager's credentials.
+    The challenge is built from the auth template and its time must be fresh.
+
+    `,
+		Fields:         fields,
+		ExistenceCheck: b.pathExistenceCheck,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.signAuth,
+			},
+		},
+	}
+}
+
+func pathSignMessage(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_message",
+		HelpSynopsis: "Sign an off-chain message.",
+		HelpDescription: `
+
+    Sign the SHA3-256 hash of "\x19ICON Signed Message:\n", the decimal byte length of the message and the message.
+    The prefix keeps a signed message from being a valid transaction.
 
     `,
 		Fields: map[string]*framework.FieldSchema{
-			"walletAddress": &framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"message": &framework.FieldSchema{
 				Type:        framework.TypeString,
-				Description: "walletAddress, It is forcibly converted to the registered account name.",
+				Description: "Message to sign",
+				Default:     "",
 			},
-			"time": &framework.FieldSchema{
-				Type:        framework.TypeInt,
-				Description: "Timestamp seconds (INT)",
-				Default:     0,
+			"encoding": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Encoding of the message, utf8 or hex",
+				Default:     "utf8",
 			},
+			"signature_format": signatureFormatField(),
 		},
-		ExistenceCheck: b.pathExistenceCheck,
 		Operations: map[logical.Operation]framework.OperationHandler{
-			logical.CreateOperation: &framework.PathOperation{
-				Callback: b.signAuth,
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signMessage,
 			},
 		},
 	}
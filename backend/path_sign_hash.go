@@ -0,0 +1,87 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathSignHash(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_hash",
+		HelpSynopsis: "Sign a raw 32-byte digest.",
+		HelpDescription: `
+
+    Sign a precomputed 32-byte digest. The signer cannot see what the digest commits to,
+    so it is disabled unless enabled for the account, and every signature is audited.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"hash": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex of the 32-byte digest to sign",
+				Default:     "",
+			},
+			"signature_format": signatureFormatField(),
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signHash,
+			},
+		},
+	}
+}
+
+func pathEnableSignHash(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_hash/enable",
+		HelpSynopsis: "Allow an ICON account to sign raw digests.",
+		HelpDescription: `
+
+    Allow the account to sign raw 32-byte digests with sign_hash.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.enableSignHash,
+			},
+		},
+	}
+}
+
+func pathDisableSignHash(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/sign_hash/disable",
+		HelpSynopsis: "Forbid an ICON account to sign raw digests.",
+		HelpDescription: `
+
+    Forbid the account to sign raw 32-byte digests with sign_hash.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.disableSignHash,
+			},
+		},
+	}
+}
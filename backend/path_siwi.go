@@ -0,0 +1,105 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathSIWISign(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "accounts/" + framework.GenericNameRegex("name") + "/siwi",
+		HelpSynopsis: "Build and sign a Sign-In-With-ICON login message.",
+		HelpDescription: `
+
+    Build a login message modeled on EIP-4361 for the account and sign its SHA3-256 hash.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{Type: framework.TypeString},
+			"domain": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Domain requesting the sign-in",
+				Default:     "",
+			},
+			"uri": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "URI of the resource the sign-in is for",
+				Default:     "",
+			},
+			"statement": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Single-line statement shown to the user",
+				Default:     "",
+			},
+			"nonce": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Nonce issued by the relying service, at least 8 alphanumeric characters. Generated if empty.",
+				Default:     "",
+			},
+			"ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Time from now until the message expires",
+				Default:     DefaultSIWITTL,
+			},
+			"signature_format": signatureFormatField(),
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.signSIWI,
+			},
+		},
+	}
+}
+
+func pathSIWIVerify(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "siwi/verify",
+		HelpSynopsis: "Verify a Sign-In-With-ICON login message.",
+		HelpDescription: `
+
+    Parse the login message, check its nonce, domain and expiration, and recover the signer's address.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"message": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Text of the signed login message",
+				Default:     "",
+			},
+			"signature": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 or 0x-prefixed hex of the [R|S|V] signature",
+				Default:     "",
+			},
+			"nonce": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Nonce the relying service issued for this sign-in",
+				Default:     "",
+			},
+			"domain": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Expected domain",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.verifySIWI,
+			},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathVerify(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern:      "verify",
+		HelpSynopsis: "Verify a signature and recover the signer.",
+		HelpDescription: `
+
+    Verify the signature of a signed ICON v3 transaction, a sign_auth style message, a sign_message message or a raw hash.
+    The signer's address is recovered and compared with the expected address and the managed accounts.
+    valid is only returned with an expected address, as any signature recovers some signer.
+
+    `,
+		Fields: map[string]*framework.FieldSchema{
+			"transaction": &framework.FieldSchema{
+				Type:        framework.TypeMap,
+				Description: "Signed ICON v3 transaction params. Its signature is used if signature is not given.",
+			},
+			"message": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Message signed with its SHA3-256 hash, as sign_auth signs the address and the time",
+				Default:     "",
+			},
+			"signed_message": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Message signed by sign_message, with its ICON message prefix",
+				Default:     "",
+			},
+			"encoding": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Encoding of signed_message, utf8 or hex",
+				Default:     "utf8",
+			},
+			"hash": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Hex of the signed 32-byte hash",
+				Default:     "",
+			},
+			"signature": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Base64 or 0x-prefixed hex of the [R|S|V] signature",
+				Default:     "",
+			},
+			"address": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "(optional) Expected signer address or alias",
+				Default:     "",
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.verifySignature,
+			},
+		},
+	}
+}
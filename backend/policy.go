@@ -0,0 +1,152 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// signingPolicy is a named check an account must pass before it signs.
+type signingPolicy struct {
+	name  string
+	check func(account *Account) error
+}
+
+// transactionSigningPolicies are the checks applied before signing a
+// transaction or a message.
+var transactionSigningPolicies = []signingPolicy{
+	{
+		name: "active_account",
+		check: func(account *Account) error {
+			if !account.IsActive() {
+				return fmt.Errorf("Signing account %s is %s", account.Address, account.Status)
+			}
+			return nil
+		},
+	},
+	{
+		name: "secp256k1_key",
+		check: func(account *Account) error {
+			if !account.IsSECP256K1() {
+				return fmt.Errorf("Signing account %s is a %s key", account.Address, account.KeyType)
+			}
+			return nil
+		},
+	},
+}
+
+// permissionFlag returns the flag of an opt-in permission of the account,
+// or nil for an unknown permission.
+func permissionFlag(account *Account, permission string) *bool {
+	switch permission {
+	case "sign_hash":
+		return &account.AllowSignHash
+	case "key_agreement":
+		return &account.AllowKeyAgreement
+	default:
+		return nil
+	}
+}
+
+// permissionPolicy requires the account to have enabled the permission.
+func permissionPolicy(permission string) signingPolicy {
+	return signingPolicy{
+		name: permission + "_enabled",
+		check: func(account *Account) error {
+			if flag := permissionFlag(account, permission); flag == nil || !*flag {
+				return fmt.Errorf("Signing account %s does not allow %s", account.Address, permission)
+			}
+			return nil
+		},
+	}
+}
+
+// setAccountPermission enables or disables an opt-in permission of the account.
+func (b *backend) setAccountPermission(ctx context.Context, req *logical.Request, name, permission string, allow bool) (*logical.Response, error) {
+	account, err := b.retrieveAccount(ctx, req, name)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("Account does not exist - %s", name)
+	}
+	if !account.IsSECP256K1() {
+		return nil, fmt.Errorf("%s is not supported for %s keys", permission, account.KeyType)
+	}
+
+	*permissionFlag(account, permission) = allow
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	event := "disable_" + permission
+	if allow {
+		event = "enable_" + permission
+	}
+	if err := b.recordAudit(ctx, req, event, account.Address, nil); err != nil {
+		return nil, err
+	}
+	b.Logger().Warn("Account permission changed", "address", account.Address, "permission", permission, "allow", allow)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":             account.Address,
+			"allow_" + permission: allow,
+		},
+	}, nil
+}
+
+// checkSigningPolicies returns the error of the first policy the account fails.
+func checkSigningPolicies(policies []signingPolicy, account *Account) error {
+	for _, policy := range policies {
+		if err := policy.check(account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluateSigningPolicies returns the result of every policy for the account,
+// without stopping at the first failure.
+func evaluateSigningPolicies(policies []signingPolicy, account *Account) (map[string]interface{}, bool) {
+	results := make(map[string]interface{}, len(policies))
+	allowed := true
+	for _, policy := range policies {
+		result := map[string]interface{}{"allowed": true}
+		if err := policy.check(account); err != nil {
+			result["allowed"] = false
+			result["reason"] = err.Error()
+			allowed = false
+		}
+		results[policy.name] = result
+	}
+	return results, allowed
+}
+
+// dryRunResponse describes what signing the transaction would produce,
+// without using the private key.
+func dryRunResponse(account *Account, serialized, txHash []byte) map[string]interface{} {
+	policies, allowed := evaluateSigningPolicies(transactionSigningPolicies, account)
+	return map[string]interface{}{
+		"dry_run":          true,
+		"account":          account.Address,
+		"serialize":        BytesToString(serialized),
+		"transaction_hash": "0x" + hex.EncodeToString(txHash),
+		"policies":         policies,
+		"allowed":          allowed,
+	}
+}
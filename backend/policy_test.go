@@ -0,0 +1,126 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamSignDryRun(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	paramSign := func(dryRun bool) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/param_sign")
+		req.Storage = storage
+		req.Data = map[string]interface{}{
+			"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+			"stepLimit": "0x4a817c800",
+			"value":     "0x2386f26fc10000",
+			"nid":       "0x53",
+			"nonce":     "0x1d",
+			"version":   "0x3",
+			"timestamp": "0x5d3d0b7b3c1e0",
+			"dry_run":   dryRun,
+		}
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	dryRun, err := paramSign(true)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(t, dryRun.Data["signature"])
+	assert.Equal(t, true, dryRun.Data["allowed"])
+	assert.Equal(t, map[string]interface{}{
+		"active_account": map[string]interface{}{"allowed": true},
+		"secp256k1_key":  map[string]interface{}{"allowed": true},
+	}, dryRun.Data["policies"])
+
+	signed, err := paramSign(false)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, signed.Data["serialize"], dryRun.Data["serialize"])
+	assert.Equal(t, signed.Data["txHash"], dryRun.Data["transaction_hash"])
+
+	// a disabled account reports the failing policy instead of an error
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/disable")
+	req.Storage = storage
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	dryRun, err = paramSign(true)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, false, dryRun.Data["allowed"])
+	assert.Equal(t, map[string]interface{}{
+		"allowed": false,
+		"reason":  "Signing account " + address + " is disabled",
+	}, dryRun.Data["policies"].(map[string]interface{})["active_account"])
+	_, err = paramSign(false)
+	assert.EqualError(t, err, "Signing account "+address+" is disabled")
+}
+
+func TestSignDryRun(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	sign := func(dryRun bool) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/sign")
+		req.Storage = storage
+		req.Data = map[string]interface{}{
+			"params": map[string]interface{}{
+				"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+				"stepLimit": "0x4a817c800",
+				"value":     "0x2386f26fc10000",
+				"timestamp": "0x185cf742ec0",
+			},
+			"dry_run": dryRun,
+		}
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	dryRun, err := sign(true)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(t, dryRun.Data["signature"])
+	assert.Equal(t, true, dryRun.Data["allowed"])
+
+	signed, err := sign(false)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, signed.Data["serializeText"], dryRun.Data["serialize"])
+	assert.Equal(t, signed.Data["transaction_hash"], dryRun.Data["transaction_hash"])
+}
@@ -0,0 +1,258 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	// openpgp falls back to RIPEMD160 for keys without hash preferences
+	_ "golang.org/x/crypto/ripemd160"
+)
+
+const (
+	ageIntro        = "age-encryption.org/v1"
+	ageX25519Label  = "age-encryption.org/v1/X25519"
+	ageArmorType    = "AGE ENCRYPTED FILE"
+	ageRecipientHRP = "age"
+	pgpMessageType  = "PGP MESSAGE"
+)
+
+// EncryptToRecipient encrypts the plaintext to a custodian public key, either
+// an age X25519 recipient (age1...) or a PGP public key, armored or base64
+// encoded. The result is ASCII armored.
+func EncryptToRecipient(recipient string, plaintext []byte) (string, error) {
+	recipient = strings.TrimSpace(recipient)
+	if strings.HasPrefix(strings.ToLower(recipient), ageRecipientHRP+"1") {
+		return encryptAge(recipient, plaintext)
+	}
+	return encryptPGP(recipient, plaintext)
+}
+
+func encryptPGP(publicKey string, plaintext []byte) (string, error) {
+	var entities openpgp.EntityList
+	var err error
+	if strings.HasPrefix(publicKey, "-----BEGIN") {
+		entities, err = openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	} else {
+		var raw []byte
+		if raw, err = base64.StdEncoding.DecodeString(publicKey); err != nil {
+			return "", fmt.Errorf("invalid PGP public key, expected armored or base64")
+		}
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(raw))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the PGP public key: %v", err)
+	}
+	if len(entities) != 1 {
+		return "", fmt.Errorf("expected a single PGP public key, got %d", len(entities))
+	}
+
+	buf := &bytes.Buffer{}
+	armored, err := armor.Encode(buf, pgpMessageType, nil)
+	if err != nil {
+		return "", err
+	}
+	w, err := openpgp.Encrypt(armored, entities, nil, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := armored.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// encryptAge produces an armored age v1 file with a single X25519 recipient
+// stanza. The plaintext is expected to be small, so the payload is a single
+// final STREAM chunk.
+func encryptAge(recipient string, plaintext []byte) (string, error) {
+	recipientKey, err := parseAgeRecipient(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	fileKey := make([]byte, 16)
+	ephemeral := make([]byte, curve25519.ScalarSize)
+	nonce := make([]byte, 16)
+	for _, b := range [][]byte{fileKey, ephemeral, nonce} {
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+	}
+	return sealAge(recipientKey, plaintext, fileKey, ephemeral, nonce)
+}
+
+// sealAge builds the age file from the given file key, ephemeral X25519
+// scalar and payload nonce.
+func sealAge(recipientKey, plaintext, fileKey, ephemeral, nonce []byte) (string, error) {
+	ephemeralShare, err := curve25519.X25519(ephemeral, curve25519.Basepoint)
+	if err != nil {
+		return "", err
+	}
+	sharedSecret, err := curve25519.X25519(ephemeral, recipientKey)
+	if err != nil {
+		return "", err
+	}
+	salt := append(append([]byte{}, ephemeralShare...), recipientKey...)
+	wrappingKey, err := hkdfSHA256(sharedSecret, salt, ageX25519Label)
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := chachaSeal(wrappingKey, make([]byte, chacha20poly1305.NonceSize), fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	b64 := base64.RawStdEncoding
+	header := &bytes.Buffer{}
+	header.WriteString(ageIntro + "\n")
+	header.WriteString("-> X25519 " + b64.EncodeToString(ephemeralShare) + "\n")
+	header.WriteString(b64.EncodeToString(wrappedKey) + "\n")
+	header.WriteString("---")
+	macKey, err := hkdfSHA256(fileKey, nil, "header")
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(header.Bytes())
+	header.WriteString(" " + b64.EncodeToString(mac.Sum(nil)) + "\n")
+
+	payloadKey, err := hkdfSHA256(fileKey, nonce, "payload")
+	if err != nil {
+		return "", err
+	}
+	// STREAM nonce of the first and last chunk: counter 0 with the last chunk flag
+	chunkNonce := make([]byte, chacha20poly1305.NonceSize)
+	chunkNonce[len(chunkNonce)-1] = 1
+	payload, err := chachaSeal(payloadKey, chunkNonce, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	file := append(header.Bytes(), nonce...)
+	file = append(file, payload...)
+	return string(pem.EncodeToMemory(&pem.Block{Type: ageArmorType, Bytes: file})), nil
+}
+
+func hkdfSHA256(secret, salt []byte, info string) ([]byte, error) {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func chachaSeal(key, nonce, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// parseAgeRecipient decodes the bech32 age1... recipient into the X25519 public key.
+func parseAgeRecipient(recipient string) ([]byte, error) {
+	if strings.ToLower(recipient) != recipient && strings.ToUpper(recipient) != recipient {
+		return nil, errors.New("invalid age recipient, mixed case")
+	}
+	recipient = strings.ToLower(recipient)
+	sep := strings.LastIndex(recipient, "1")
+	if sep < 1 || len(recipient)-sep < 7 {
+		return nil, errors.New("invalid age recipient")
+	}
+	hrp := recipient[:sep]
+	if hrp != ageRecipientHRP {
+		return nil, fmt.Errorf("invalid age recipient prefix - %s", hrp)
+	}
+	var values []byte
+	for _, c := range recipient[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return nil, fmt.Errorf("invalid age recipient character - %c", c)
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), values...)) != 1 {
+		return nil, errors.New("invalid age recipient checksum")
+	}
+
+	// convert the 5-bit groups, without the checksum, into bytes
+	var key []byte
+	acc, bits := 0, 0
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | int(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			key = append(key, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return nil, errors.New("invalid age recipient padding")
+	}
+	if len(key) != curve25519.PointSize {
+		return nil, fmt.Errorf("invalid age recipient length - %d", len(key))
+	}
+	return key, nil
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range []byte(hrp) {
+		expanded = append(expanded, c>>5)
+	}
+	expanded = append(expanded, 0)
+	for _, c := range []byte(hrp) {
+		expanded = append(expanded, c&31)
+	}
+	return expanded
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
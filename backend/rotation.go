@@ -0,0 +1,318 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// DefaultStepPrice is the step price of the ICON mainnet, 12.5 Gloop
+const DefaultStepPrice = "0x2e90edd00"
+
+// signTransactionParams signs the v3 transaction params and returns the
+// transaction hash and the base64 signature.
+func signTransactionParams(params map[string]interface{}, privateKey *PrivateKey) ([]byte, string, error) {
+	fields, _ := transactionFields[Version3]
+	res, err := SerializeMap(params, fields.inclusion, fields.exclusion)
+	if err != nil {
+		return nil, "", err
+	}
+	txHash := SHA3Sum256(append(transactionSaltBytes, res...))
+	signature, err := NewSignature(txHash, privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	b64Sig, err := signature.EncodeBase64()
+	if err != nil {
+		return nil, "", err
+	}
+	return txHash, b64Sig, nil
+}
+
+func (b *backend) rotateAccount(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("name").(string)
+	account, err := b.retrieveAccount(ctx, req, address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Account does not exist - %s", address)
+	}
+	if !account.IsActive() {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Account is %s - %s", account.Status, account.Address)
+	}
+	if !account.IsSECP256K1() {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Rotation is not supported for %s keys", account.KeyType)
+	}
+
+	balance, value, stepLimit, err := parseRotationTransfer(data)
+	if err != nil {
+		return nil, err
+	}
+	// without a transfer whatever is left on the account stays behind the retired key
+	if balance.Sign() == 0 && !data.Get("force").(bool) {
+		return nil, fmt.Errorf("[ROTATE][FAIL] A zero balance retires the account without a transfer, use force to confirm")
+	}
+
+	privateKey, publicKey := GenerateKey()
+	var wallet *HDWallet
+	var derivationPath string
+	if account.HDWallet != "" {
+		// the successor is derived from the same wallet, which keeps covering the key
+		lock := locksutil.LockForKey(b.hdWalletLocks, account.HDWallet)
+		lock.Lock()
+		defer lock.Unlock()
+
+		if wallet, err = b.retrieveHDWallet(ctx, req, account.HDWallet); err != nil {
+			return nil, err
+		}
+		if wallet == nil {
+			return nil, fmt.Errorf("[ROTATE][FAIL] HD wallet does not exist - %s", account.HDWallet)
+		}
+		if wallet.Exported && !account.Exportable {
+			return nil, fmt.Errorf("HD wallet %s exists outside the plugin, derived accounts must be exportable", wallet.Name)
+		}
+		if privateKey, derivationPath, err = deriveRotationKey(wallet, account.DerivationPath); err != nil {
+			return nil, err
+		}
+		publicKey = privateKey.PublicKey()
+	}
+	aliasName := data.Get("alias_name").(string)
+	if aliasName == "" {
+		aliasName = account.AliasName
+	}
+	if aliasName != account.AliasName {
+		if _, err := b.checkAccountConflict(ctx, req, publicKey.Address(), aliasName, false); err != nil {
+			return nil, err
+		}
+	}
+	successor := &Account{
+		Address:        publicKey.Address(),
+		PrivateKey:     privateKey.String(),
+		PublicKey:      publicKey.String(),
+		AliasName:      aliasName,
+		HDWallet:       account.HDWallet,
+		DerivationPath: derivationPath,
+		Exportable:     account.Exportable,
+		RotatedFrom:    account.Address,
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"address":      successor.Address,
+			"alias_name":   successor.AliasName,
+			"rotated_from": account.Address,
+		},
+	}
+	if derivationPath != "" {
+		resp.Data["derivation_path"] = derivationPath
+	}
+	if balance.Sign() > 0 {
+		if err := b.signRotationTransfer(account, successor.Address, value, stepLimit, data, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.storeAccount(ctx, req, successor); err != nil {
+		return nil, err
+	}
+	if wallet != nil {
+		wallet.NextIndex++
+		if err := b.storeHDWallet(ctx, req, wallet); err != nil {
+			return nil, err
+		}
+	}
+	// the successor takes over the alias of the retired account
+	account.AliasName = ""
+	account.Status = AccountStatusRetired
+	account.RotatedTo = successor.Address
+	if err := b.storeAccount(ctx, req, account); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "rotate", account.Address, map[string]interface{}{
+		"successor": successor.Address,
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[ROTATE][OK] Rotated account", "address", account.Address, "successor", successor.Address)
+	return resp, nil
+}
+
+// resignRotationTransfer signs the transfer of the balance of a retired
+// account to its successor again, for when the transfer returned by the
+// rotation was dropped or failed. The retired key signs nothing else.
+func (b *backend) resignRotationTransfer(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("name").(string)
+	account, err := b.retrieveAccount(ctx, req, address)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Account does not exist - %s", address)
+	}
+	if account.Status != AccountStatusRetired || account.RotatedTo == "" {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Account has not been rotated - %s", account.Address)
+	}
+	balance, value, stepLimit, err := parseRotationTransfer(data)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Sign() == 0 {
+		return nil, fmt.Errorf("[ROTATE][FAIL] Nothing to transfer from a zero balance")
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"address":    account.Address,
+			"rotated_to": account.RotatedTo,
+		},
+	}
+	if err := b.signRotationTransfer(account, account.RotatedTo, value, stepLimit, data, resp); err != nil {
+		return nil, err
+	}
+	if err := b.recordAudit(ctx, req, "rotate_transfer", account.Address, map[string]interface{}{
+		"successor": account.RotatedTo,
+		"value":     "0x" + value.Text(16),
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[ROTATE][OK] Signed the transfer to the successor again", "address", account.Address, "successor", account.RotatedTo)
+	return resp, nil
+}
+
+// parseRotationTransfer returns the balance of the rotated account, the value
+// left to transfer after the fee and the stepLimit of the transfer.
+func parseRotationTransfer(data *framework.FieldData) (*big.Int, *big.Int, *big.Int, error) {
+	rawBalance := data.Get("balance").(string)
+	if rawBalance == "" {
+		return nil, nil, nil, fmt.Errorf("balance is required")
+	}
+	balance, ok := ParseBig256(rawBalance)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("Invalid balance")
+	}
+	stepLimit, ok := ParseBig256(data.Get("stepLimit").(string))
+	if !ok || stepLimit.Sign() <= 0 {
+		return nil, nil, nil, fmt.Errorf("Invalid stepLimit")
+	}
+	stepPrice, ok := ParseBig256(data.Get("stepPrice").(string))
+	if !ok || stepPrice.Sign() <= 0 {
+		return nil, nil, nil, fmt.Errorf("Invalid stepPrice")
+	}
+	fee := new(big.Int).Mul(stepLimit, stepPrice)
+	value := new(big.Int).Sub(balance, fee)
+	if balance.Sign() > 0 && value.Sign() <= 0 {
+		return nil, nil, nil, fmt.Errorf("balance %s does not cover the fee %s", balance, fee)
+	}
+	return balance, value, stepLimit, nil
+}
+
+// signRotationTransfer signs the transfer of value from the rotated account
+// to its successor and adds the transaction to the response.
+func (b *backend) signRotationTransfer(account *Account, to string, value, stepLimit *big.Int, data *framework.FieldData, resp *logical.Response) error {
+	privateKey, err := ParsePrivateKeyFromString(account.PrivateKey)
+	if err != nil {
+		return err
+	}
+	timestamp := data.Get("timestamp").(string)
+	if timestamp == "" {
+		timestamp = TimeStampNow()
+	}
+	params := map[string]interface{}{
+		"version":   "0x3",
+		"from":      account.Address,
+		"to":        to,
+		"value":     "0x" + value.Text(16),
+		"stepLimit": "0x" + stepLimit.Text(16),
+		"timestamp": timestamp,
+		"nid":       data.Get("nid").(string),
+	}
+	if nonce := data.Get("nonce").(string); nonce != "" {
+		params["nonce"] = nonce
+	}
+	txHash, signature, err := signTransactionParams(params, privateKey)
+	if err != nil {
+		b.Logger().Error("[ROTATE][FAIL] Failed to sign the transfer", "address", account.Address, "error", err)
+		return err
+	}
+	params["signature"] = signature
+	resp.Data["transaction_hash"] = "0x" + hex.EncodeToString(txHash)
+	resp.Data["transaction"] = params
+	return nil
+}
+
+// deriveRotationKey derives the key at the next index of the wallet, under
+// the BIP-44 account of the rotated key.
+func deriveRotationKey(wallet *HDWallet, rotatedPath string) (*PrivateKey, string, error) {
+	var accountIndex uint32
+	if indexes, err := ParseDerivationPath(rotatedPath); err == nil && len(indexes) == 5 && indexes[2] >= HardenedKeyStart {
+		accountIndex = indexes[2] - HardenedKeyStart
+	}
+	if wallet.NextIndex >= HardenedKeyStart {
+		return nil, "", fmt.Errorf("HD wallet %s has no index left", wallet.Name)
+	}
+	seed, err := hex.DecodeString(wallet.Seed)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode the stored seed: %v", err)
+	}
+	derivationPath := ICONDerivationPath(accountIndex, wallet.NextIndex)
+	privateKey, err := DerivePrivateKey(seed, derivationPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return privateKey, derivationPath, nil
+}
+
+func (b *backend) listRotationDue(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.retrieveConfig(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxKeyAge <= 0 {
+		return logical.ListResponse(nil), nil
+	}
+	addresses, index, err := b.listAllAccountIndex(ctx, req, &accountFilter{})
+	if err != nil {
+		b.Logger().Error("Failed to retrieve the list of accounts", "error", err)
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	var keys []string
+	keyInfo := map[string]interface{}{}
+	for _, address := range addresses {
+		entry := index[address]
+		if !entry.isActive() {
+			continue
+		}
+		// accounts stored before the creation time was recorded have an unknown age
+		if age := now - entry.CreatedAt; entry.CreatedAt == 0 || age >= config.MaxKeyAge {
+			keys = append(keys, address)
+			keyInfo[address] = map[string]interface{}{
+				"alias_name": entry.AliasName,
+				"created_at": entry.CreatedAt,
+			}
+		}
+	}
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
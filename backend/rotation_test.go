@@ -0,0 +1,205 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateAccount(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"name": "hot_wallet",
+	}
+	resp, _ := b.HandleRequest(context.Background(), req)
+	address := resp.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/rotate")
+	req.Storage = storage
+	_, err := b.HandleRequest(context.Background(), req)
+	assert.Equal("balance is required", err.Error())
+
+	req.Data = map[string]interface{}{"balance": "0x0"}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("[ROTATE][FAIL] A zero balance retires the account without a transfer, use force to confirm", err.Error())
+
+	req.Data = map[string]interface{}{
+		"balance":   "0x1000",
+		"stepLimit": "0x100",
+		"stepPrice": "0x10",
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("balance 4096 does not cover the fee 4096", err.Error())
+
+	req.Data["balance"] = "0xde0b6b3a7640000"
+	req.Data["timestamp"] = "0x5e5d940e41678"
+	req.Data["nid"] = "0x53"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	successor := resp.Data["address"].(string)
+	assert.Equal("hot_wallet", resp.Data["alias_name"])
+	assert.Equal(address, resp.Data["rotated_from"])
+
+	transaction := resp.Data["transaction"].(map[string]interface{})
+	assert.Equal(address, transaction["from"])
+	assert.Equal(successor, transaction["to"])
+	assert.Equal("0xde0b6b3a763f000", transaction["value"])
+
+	// the transfer is signed by the retired key
+	txHash, _ := DecodeStringToBytes(resp.Data["transaction_hash"].(string))
+	signature := toSignatureBS(transaction["signature"].(string))
+	publicKey, err := signature.RecoverPublicKey(txHash)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, publicKey.Address())
+
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/param_sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+		"stepLimit": "0x4a817c800",
+		"value":     "0x2386f26fc10000",
+	}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("Signing account %s is retired", address), err.Error())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/rotate")
+	req.Storage = storage
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[ROTATE][FAIL] Account is retired - %s", address), err.Error())
+
+	// a dropped transfer is signed again, only to the successor
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/rotate/transfer")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"balance": "0"}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal("[ROTATE][FAIL] Nothing to transfer from a zero balance", err.Error())
+
+	req.Data = map[string]interface{}{
+		"balance":   "0xde0b6b3a7640000",
+		"stepLimit": "0x30d40",
+		"timestamp": "0x5e5d940e41679",
+		"nid":       "0x53",
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(successor, resp.Data["rotated_to"])
+	transaction = resp.Data["transaction"].(map[string]interface{})
+	assert.Equal(address, transaction["from"])
+	assert.Equal(successor, transaction["to"])
+	assert.Equal("0x30d40", transaction["stepLimit"])
+	assert.Equal("0xdd7d4f70b73c000", transaction["value"])
+	txHash, _ = DecodeStringToBytes(resp.Data["transaction_hash"].(string))
+	publicKey, err = toSignatureBS(transaction["signature"].(string)).RecoverPublicKey(txHash)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(address, publicKey.Address())
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+successor+"/rotate/transfer")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"balance": "0xde0b6b3a7640000"}
+	_, err = b.HandleRequest(context.Background(), req)
+	assert.Equal(fmt.Sprintf("[ROTATE][FAIL] Account has not been rotated - %s", successor), err.Error())
+
+	account, _ := b.(*backend).retrieveAccount(context.Background(), req, address)
+	assert.Equal(successor, account.RotatedTo)
+
+	// the successor holds nothing yet and is retired without a transfer
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+successor+"/rotate")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"balance": "0", "force": true}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(resp.Data["transaction"])
+	account, _ = b.(*backend).retrieveAccount(context.Background(), req, successor)
+	assert.Equal(AccountStatusRetired, account.Status)
+}
+
+func TestRotateHDAccount(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+	call := func(path string, data map[string]interface{}) *logical.Response {
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.Storage = storage
+		req.WrapInfo = &logical.RequestWrapInfo{TTL: time.Minute}
+		req.Data = data
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	call("hd", map[string]interface{}{"name": "treasury"})
+	address := call("hd/treasury/derive", map[string]interface{}{"account": 1, "exportable": true}).Data["address"].(string)
+
+	resp := call("accounts/"+address+"/rotate", map[string]interface{}{"balance": "0", "force": true})
+	successor := resp.Data["address"].(string)
+	assert.Equal("m/44'/74'/1'/0/1", resp.Data["derivation_path"])
+
+	// the successor is covered by the wallet
+	wallet, _ := b.(*backend).retrieveHDWallet(context.Background(), &logical.Request{Storage: storage}, "treasury")
+	assert.Equal(uint32(2), wallet.NextIndex)
+	seed, _ := hex.DecodeString(wallet.Seed)
+	privateKey, err := DerivePrivateKey(seed, "m/44'/74'/1'/0/1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(successor, privateKey.PublicKey().Address())
+	account, _ := b.(*backend).retrieveAccount(context.Background(), &logical.Request{Storage: storage}, successor)
+	assert.Equal("treasury", account.HDWallet)
+	assert.True(account.Exportable)
+}
+
+func TestRotationDue(t *testing.T) {
+	assert := assert.New(t)
+	b, storage := getBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	_, _ = b.HandleRequest(context.Background(), req)
+
+	req = logical.TestRequest(t, logical.ListOperation, "rotation/due")
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Nil(resp.Data["keys"])
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "config")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"max_key_age": "1s",
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(int64(1), resp.Data["max_key_age"])
+
+	time.Sleep(time.Second)
+	req = logical.TestRequest(t, logical.ListOperation, "rotation/due")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(1, len(resp.Data["keys"].([]string)))
+}
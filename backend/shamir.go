@@ -0,0 +1,148 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ShamirSplit splits the secret into parts shares, any threshold of which
+// rebuild it. Each share is the evaluated bytes followed by its x coordinate,
+// the same layout as the Vault unseal key shares.
+func ShamirSplit(secret []byte, parts, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+	if parts < threshold {
+		return nil, fmt.Errorf("shares (%d) cannot be less than the threshold (%d)", parts, threshold)
+	}
+	if parts > 255 {
+		return nil, fmt.Errorf("shares cannot exceed 255 - input: %d", parts)
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2 - input: %d", threshold)
+	}
+
+	xCoordinates, err := shamirXCoordinates(parts)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = xCoordinates[i]
+	}
+
+	coefficients := make([]byte, threshold)
+	for idx, value := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		coefficients[0] = value
+		for i, x := range xCoordinates {
+			shares[i][idx] = gfEvaluate(coefficients, x)
+		}
+	}
+	return shares, nil
+}
+
+// ShamirCombine rebuilds the secret from the shares. Combining fewer shares
+// than the threshold returns a wrong secret, not an error.
+func ShamirCombine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least 2 shares are required")
+	}
+	length := len(shares[0])
+	if length < 2 {
+		return nil, errors.New("shares must be at least 2 bytes")
+	}
+	xs := make([]byte, len(shares))
+	seen := map[byte]bool{}
+	for i, share := range shares {
+		if len(share) != length {
+			return nil, errors.New("all shares must be the same length")
+		}
+		x := share[length-1]
+		if x == 0 || seen[x] {
+			return nil, errors.New("duplicate or invalid share")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, length-1)
+	for i, share := range shares {
+		// Lagrange basis polynomial of share i evaluated at 0
+		basis := byte(1)
+		for j, x := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(x, x^xs[i]))
+		}
+		for idx := range secret {
+			secret[idx] ^= gfMul(share[idx], basis)
+		}
+	}
+	return secret, nil
+}
+
+// shamirXCoordinates returns distinct, non-zero random x coordinates.
+func shamirXCoordinates(parts int) ([]byte, error) {
+	candidates := make([]byte, 255)
+	for i := range candidates {
+		candidates[i] = byte(i + 1)
+	}
+	random := make([]byte, len(candidates))
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	for i := len(candidates) - 1; i > 0; i-- {
+		j := int(random[i]) % (i + 1)
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	}
+	return candidates[:parts], nil
+}
+
+// gfEvaluate evaluates the polynomial at x with Horner's method.
+func gfEvaluate(coefficients []byte, x byte) byte {
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = gfMul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// gfMul multiplies in GF(2^8) with the AES polynomial, without data dependent branches.
+func gfMul(a, b byte) byte {
+	var result byte
+	for i := 0; i < 8; i++ {
+		result ^= a & -(b & 1)
+		a = (a << 1) ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+	return result
+}
+
+// gfDiv divides in GF(2^8), using a^254 as the inverse of a.
+func gfDiv(a, b byte) byte {
+	inverse := b
+	for i := 0; i < 6; i++ {
+		inverse = gfMul(gfMul(inverse, inverse), b)
+	}
+	return gfMul(a, gfMul(inverse, inverse))
+}
@@ -0,0 +1,76 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// rawHashSigningPolicies are the checks applied before signing a raw digest,
+// which is only allowed for the accounts that enabled it.
+var rawHashSigningPolicies = append(append([]signingPolicy{}, transactionSigningPolicies...), permissionPolicy("sign_hash"))
+
+func (b *backend) enableSignHash(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountPermission(ctx, req, data.Get("name").(string), "sign_hash", true)
+}
+
+func (b *backend) disableSignHash(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return b.setAccountPermission(ctx, req, data.Get("name").(string), "sign_hash", false)
+}
+
+func (b *backend) signHash(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	// unlike NewSignature, a short hash is not padded
+	hash, err := hex.DecodeString(strings.TrimPrefix(data.Get("hash").(string), "0x"))
+	if err != nil || len(hash) != HashLen {
+		return nil, fmt.Errorf("hash must be %d bytes of hex", HashLen)
+	}
+	account, privateKey, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), rawHashSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	signedHash, err := NewSignature(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signedHash.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
+	// the signature is only returned once the blind signing is recorded
+	if err := b.recordAudit(ctx, req, "sign_hash", account.Address, map[string]interface{}{
+		"hash": "0x" + hex.EncodeToString(hash),
+	}); err != nil {
+		return nil, err
+	}
+	b.Logger().Warn("[SIGN][OK] Signed a raw hash", "address", account.Address, "hash", hex.EncodeToString(hash))
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":   account.Address,
+			"hash":      "0x" + hex.EncodeToString(hash),
+			"signature": signature,
+		},
+	}, nil
+}
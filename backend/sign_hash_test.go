@@ -0,0 +1,104 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignHash(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+	digest := SHA3Sum256([]byte("btp message"))
+
+	signHash := func(hash string) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/sign_hash")
+		req.Storage = storage
+		req.Data = map[string]interface{}{"hash": hash}
+		return b.HandleRequest(context.Background(), req)
+	}
+	setSignHash := func(action string) {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/sign_hash/"+action)
+		req.Storage = storage
+		if _, err := b.HandleRequest(context.Background(), req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	// disabled by default
+	_, err = signHash(hex.EncodeToString(digest))
+	assert.EqualError(t, err, "Signing account "+address+" does not allow sign_hash")
+
+	setSignHash("enable")
+	req = logical.TestRequest(t, logical.ReadOperation, "accounts/"+address)
+	req.Storage = storage
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, true, resp.Data["allow_sign_hash"])
+
+	resp, err = signHash("0x" + hex.EncodeToString(digest))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	sig, err := ParseSignatureString(resp.Data["signature"].(string))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	publicKey, err := sig.RecoverPublicKey(digest)
+	assert.Nil(t, err)
+	assert.Equal(t, address, publicKey.Address())
+
+	// a short digest is not padded
+	_, err = signHash(hex.EncodeToString(digest[1:]))
+	assert.EqualError(t, err, "hash must be 32 bytes of hex")
+	_, err = signHash("zz")
+	assert.EqualError(t, err, "hash must be 32 bytes of hex")
+
+	// every raw hash signature is audited
+	req = logical.TestRequest(t, logical.ListOperation, "audit")
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	auditIDs := resp.Data["keys"].([]string)
+	assert.Equal(t, 2, len(auditIDs))
+	req = logical.TestRequest(t, logical.ReadOperation, "audit/"+auditIDs[1])
+	req.Storage = storage
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "sign_hash", resp.Data["event"])
+	assert.Equal(t, address, resp.Data["address"])
+	assert.Equal(t, "0x"+hex.EncodeToString(digest), resp.Data["details"].(map[string]interface{})["hash"])
+
+	setSignHash("disable")
+	_, err = signHash(hex.EncodeToString(digest))
+	assert.EqualError(t, err, "Signing account "+address+" does not allow sign_hash")
+}
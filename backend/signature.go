@@ -15,15 +15,17 @@
 package backend
 
 import (
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
-
 	"errors"
 	"fmt"
-	"github.com/haltingstate/secp256k1-go"
-	"github.com/k0kubun/pp/v3"
-	"sync"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 )
 
 const (
@@ -36,24 +38,114 @@ const (
 	HashLen = 32
 )
 
+const (
+	// SignatureFormatBase64RSV is the base64 of [R|S|V], used by ICON transactions
+	SignatureFormatBase64RSV = "base64-rsv"
+	// SignatureFormatHexRSV is the 0x-prefixed hex of [R|S|V]
+	SignatureFormatHexRSV = "hex-rsv"
+	// SignatureFormatHexVRS is the 0x-prefixed hex of [V|R|S]
+	SignatureFormatHexVRS = "hex-vrs"
+	// SignatureFormatCompactRS is the 0x-prefixed hex of [R|S], without V
+	SignatureFormatCompactRS = "compact-rs"
+	// SignatureFormatSplit is an object of the hex r and s and the integer v
+	SignatureFormatSplit = "split"
+)
+
 // Signature is a type representing an ECDSA signature with or without V.
 type Signature struct {
 	bytes []byte // 65 bytes of [R|S|V]
 	hasV  bool
 }
 
-var globalLock sync.Mutex
+// compactSigMagicOffset is added to the recovery id in the first byte of a
+// compact [V|R|S] signature, as in Bitcoin signed messages.
+const compactSigMagicOffset = 27
 
 // NewSignature calculates an ECDSA signature including V, which is 0 or 1.
+// The nonce is derived with RFC 6979 and S is normalized to the lower half
+// of the order. k*G and the inverse of the nonce are computed in constant
+// time, so the signing time does not depend on the nonce or the key. It keeps
+// no shared state, so it is safe to call concurrently.
 func NewSignature(hash []byte, privKey *PrivateKey) (*Signature, error) {
-	globalLock.Lock()
-	defer globalLock.Unlock()
-	pp.Printf("\n\n\n hash: %v", hash)
-
 	if len(hash) == 0 || len(hash) > HashLen || privKey == nil {
 		return nil, errors.New(fmt.Sprintf("Invalid arguments hash len=%d, privKey=%v", len(hash), privKey))
 	}
-	return ParseSignature(secp256k1.Sign(hash, privKey.bytes))
+	// left-pad a short hash, as it is a big-endian number
+	msg := make([]byte, HashLen)
+	copy(msg[HashLen-len(hash):], hash)
+
+	key := secp256k1.PrivKeyFromBytes(privKey.bytes)
+	defer key.Zero()
+	keyBytes := key.Key.Bytes()
+	defer zeroArray(keyBytes[:])
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(msg)
+
+	for iteration := uint32(0); ; iteration++ {
+		k := secp256k1.NonceRFC6979(keyBytes[:], msg, nil, nil, iteration)
+		sig, err := signWithNonce(&key.Key, k, &e)
+		k.Zero()
+		if err != nil {
+			return nil, err
+		}
+		// r or s is zero, retry with the next nonce
+		if sig == nil {
+			continue
+		}
+		return ParseSignature(sig)
+	}
+}
+
+// signWithNonce returns the [R|S|V] signature of e by the key d with the
+// nonce k, or nil if r or s is zero. The branches only depend on r and s,
+// which the signature reveals anyway.
+func signWithNonce(d, k, e *secp256k1.ModNScalar) ([]byte, error) {
+	point, err := scalarMultConst(k, secp256k1Generator)
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := point.SerializeUncompressed()
+	var r secp256k1.ModNScalar
+	overflow := r.SetByteSlice(uncompressed[1:33])
+	if r.IsZero() {
+		return nil, nil
+	}
+	// bit 0 of the recovery id is the oddness of R.y, bit 1 whether R.x >= n
+	v := uncompressed[64] & 1
+	if overflow {
+		v |= 2
+	}
+
+	kInv := inverseModNConst(k)
+	defer kInv.Zero()
+	s := new(secp256k1.ModNScalar).Mul2(d, &r).Add(e).Mul(kInv)
+	if s.IsZero() {
+		return nil, nil
+	}
+	// -s is the signature of the nonce -k, whose R.y has the other oddness
+	if s.IsOverHalfOrder() {
+		s.Negate()
+		v ^= 1
+	}
+
+	sig := make([]byte, SignatureLenRawWithV)
+	r.PutBytesUnchecked(sig[:32])
+	s.PutBytesUnchecked(sig[32:64])
+	sig[64] = v
+	return sig, nil
+}
+
+// IsLowS returns whether S is at most half the curve order. High-S
+// signatures are malleable and are not produced by NewSignature.
+func (sig *Signature) IsLowS() bool {
+	if len(sig.bytes) < SignatureLenRaw {
+		return false
+	}
+	var s secp256k1.ModNScalar
+	if overflow := s.SetByteSlice(sig.bytes[32:64]); overflow {
+		return false
+	}
+	return !s.IsOverHalfOrder()
 }
 
 // ParseSignature parses a signature from the raw byte array of 64([R|S]) or
@@ -147,11 +239,17 @@ func (sig *Signature) RecoverPublicKey(hash []byte) (*PublicKey, error) {
 	if len(hash) == 0 || len(hash) > HashLen {
 		return nil, errors.New("message hash is illegal")
 	}
-	s, err := sig.SerializeRSV()
+	if sig.bytes[64] > 3 {
+		return nil, errors.New("invalid V value")
+	}
+	compact := make([]byte, SignatureLenRawWithV)
+	compact[0] = compactSigMagicOffset + sig.bytes[64]
+	copy(compact[1:], sig.bytes[:SignatureLenRaw])
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
 	if err != nil {
 		return nil, err
 	}
-	return ParsePublicKey(secp256k1.RecoverPubkey(hash[:], s))
+	return ParsePublicKey(pub.SerializeCompressed())
 }
 
 // Verify verifies the signature of hash using the public key.
@@ -159,12 +257,46 @@ func (sig *Signature) Verify(msg []byte, pubKey *PublicKey) bool {
 	if len(msg) == 0 || len(msg) > HashLen || pubKey == nil {
 		return false
 	}
-	s, err := sig.SerializeRSV()
+	if !sig.IsLowS() {
+		return false
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig.bytes[:32]) || s.SetByteSlice(sig.bytes[32:64]) {
+		return false
+	}
+	pub, err := secp256k1.ParsePubKey(pubKey.bytes)
 	if err != nil {
 		return false
 	}
-	ret := secp256k1.VerifySignature(msg, s, pubKey.bytes)
-	return ret != 0
+	return ecdsa.NewSignature(&r, &s).Verify(msg, pub)
+}
+
+// SerializeDER returns the DER ECDSA-Sig-Value of [R|S], as in X.509 and TLS.
+func (sig *Signature) SerializeDER() ([]byte, error) {
+	rs, err := sig.SerializeRS()
+	if err != nil {
+		return nil, err
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(rs[:32]) || s.SetByteSlice(rs[32:]) {
+		return nil, errors.New("not a valid signature")
+	}
+	return ecdsa.NewSignature(&r, &s).Serialize(), nil
+}
+
+// ParseSignatureDER parses a DER ECDSA-Sig-Value into a signature without V.
+func ParseSignatureDER(der []byte) (*Signature, error) {
+	if _, err := ecdsa.ParseDERSignature(der); err != nil {
+		return nil, err
+	}
+	var value struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &value); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, SignatureLenRaw)
+	value.R.FillBytes(raw[:32])
+	value.S.FillBytes(raw[32:])
+	return ParseSignature(raw)
 }
 
 // String returns the string representation.
@@ -213,6 +345,48 @@ func (sig Signature) EncodeBase64() (string, error) {
 	}
 }
 
+// checkSignatureFormat returns an error for an unknown signature format, so
+// that requests fail before signing.
+func checkSignatureFormat(format string) error {
+	switch format {
+	case "", SignatureFormatBase64RSV, SignatureFormatHexRSV, SignatureFormatHexVRS, SignatureFormatCompactRS, SignatureFormatSplit:
+		return nil
+	default:
+		return fmt.Errorf("unsupported signature_format - %s", format)
+	}
+}
+
+// Encode returns the signature in the given format, which is a string except
+// for SignatureFormatSplit.
+func (sig *Signature) Encode(format string) (interface{}, error) {
+	rsv, err := sig.SerializeRSV()
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "", SignatureFormatBase64RSV:
+		return base64.StdEncoding.EncodeToString(rsv), nil
+	case SignatureFormatHexRSV:
+		return "0x" + hex.EncodeToString(rsv), nil
+	case SignatureFormatHexVRS:
+		vrs, err := sig.SerializeVRS()
+		if err != nil {
+			return nil, err
+		}
+		return "0x" + hex.EncodeToString(vrs), nil
+	case SignatureFormatCompactRS:
+		return "0x" + hex.EncodeToString(rsv[:SignatureLenRaw]), nil
+	case SignatureFormatSplit:
+		return map[string]interface{}{
+			"r": "0x" + hex.EncodeToString(rsv[:32]),
+			"s": "0x" + hex.EncodeToString(rsv[32:64]),
+			"v": int(rsv[64]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature_format - %s", format)
+	}
+}
+
 func (sig *Signature) UnmarshalJSON(s []byte) error {
 	var str string
 	err := json.Unmarshal(s, &str)
@@ -234,6 +408,25 @@ func (sig *Signature) UnmarshalJSON(s []byte) error {
 	}
 }
 
+// ParseSignatureString parses a [R|S|V] signature encoded as base64, as in
+// ICON transactions, or as 0x-prefixed hex.
+func ParseSignatureString(s string) (*Signature, error) {
+	var raw []byte
+	var err error
+	if strings.HasPrefix(s, "0x") {
+		raw, err = hex.DecodeString(s[2:])
+	} else {
+		raw, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding - %v", err)
+	}
+	if len(raw) != SignatureLenRawWithV {
+		return nil, fmt.Errorf("invalid signature length %d, expected %d", len(raw), SignatureLenRawWithV)
+	}
+	return ParseSignature(raw)
+}
+
 func toSignatureBS(s string) *Signature {
 	//var s *Signature
 	bytes, _ := base64.StdEncoding.DecodeString(s)
@@ -0,0 +1,143 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureEncode(t *testing.T) {
+	privateKey, _ := GenerateKey()
+	sig, err := NewSignature(SHA3Sum256([]byte("format")), privateKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	rsv, _ := sig.SerializeRSV()
+	vrs, _ := sig.SerializeVRS()
+
+	for format, expected := range map[string]interface{}{
+		"":                       base64.StdEncoding.EncodeToString(rsv),
+		SignatureFormatBase64RSV: base64.StdEncoding.EncodeToString(rsv),
+		SignatureFormatHexRSV:    "0x" + hex.EncodeToString(rsv),
+		SignatureFormatHexVRS:    "0x" + hex.EncodeToString(vrs),
+		SignatureFormatCompactRS: "0x" + hex.EncodeToString(rsv[:64]),
+		SignatureFormatSplit: map[string]interface{}{
+			"r": "0x" + hex.EncodeToString(rsv[:32]),
+			"s": "0x" + hex.EncodeToString(rsv[32:64]),
+			"v": int(rsv[64]),
+		},
+	} {
+		encoded, err := sig.Encode(format)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, encoded, format)
+	}
+	_, err = sig.Encode("der")
+	assert.EqualError(t, err, "unsupported signature_format - der")
+}
+
+func TestSignParamTransactionSignatureFormat(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	paramSign := func(format string) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/param_sign")
+		req.Storage = storage
+		req.Data = map[string]interface{}{
+			"to":               "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+			"stepLimit":        "0x4a817c800",
+			"value":            "0x2386f26fc10000",
+			"nid":              "0x53",
+			"nonce":            "0x1d",
+			"version":          "0x3",
+			"timestamp":        "0x5d3d0b7b3c1e0",
+			"signature_format": format,
+		}
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	base, err := paramSign(SignatureFormatBase64RSV)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// the format is not serialized into the transaction
+	assert.False(t, strings.Contains(base.Data["serialize"].(string), "signature_format"))
+	rsv, _ := base64.StdEncoding.DecodeString(base.Data["signature"].(string))
+
+	resp, err := paramSign(SignatureFormatHexVRS)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, base.Data["serialize"], resp.Data["serialize"])
+	assert.Equal(t, "0x"+hex.EncodeToString(append(rsv[64:], rsv[:64]...)), resp.Data["signature"])
+	// the signed transaction keeps the ICON encoding
+	assert.Equal(t, base.Data["signature"], resp.Data["signed_params"].(map[string]interface{})["signature"])
+
+	resp, err = paramSign(SignatureFormatSplit)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	split := resp.Data["signature"].(map[string]interface{})
+	assert.Equal(t, "0x"+hex.EncodeToString(rsv[:32]), split["r"])
+	assert.Equal(t, "0x"+hex.EncodeToString(rsv[32:64]), split["s"])
+	assert.Equal(t, int(rsv[64]), split["v"])
+
+	_, err = paramSign("der")
+	assert.EqualError(t, err, "unsupported signature_format - der")
+}
+
+func TestSignAuthSignatureFormat(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	now := time.Now().Unix()
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/sign_auth")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"time":             int(now),
+		"signature_format": SignatureFormatHexRSV,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	rsv, err := hex.DecodeString(strings.TrimPrefix(resp.Data["signature"].(string), "0x"))
+	assert.Nil(t, err)
+	sig, err := ParseSignature(rsv)
+	assert.Nil(t, err)
+	publicKey, err := sig.RecoverPublicKey(SHA3Sum256([]byte(address + strconv.FormatInt(now, 10))))
+	assert.Nil(t, err)
+	assert.Equal(t, address, publicKey.Address())
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSignatureRFC6979(t *testing.T) {
+	one, _ := ParsePrivateKey(append(make([]byte, 31), 1))
+	icx, _ := ParsePrivateKeyFromString("d25d2854b9d7d73f3119017e0159d5ee9500be8da5b93e2ddd812e20c2094c32")
+	satoshi := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	tears := sha256.Sum256([]byte("All those moments will be lost in time, like tears in rain. Time to die..."))
+	// the hash of an ICX transfer, signed as the ICON SDKs do with libsecp256k1
+	txHash, _ := hex.DecodeString("0945aca140b5a76ca6a0d4c0eade2cdad003796aa1a2d3d1e47561005f0407ce")
+
+	vectors := []struct {
+		key  *PrivateKey
+		hash []byte
+		rsv  string
+	}{
+		{one, satoshi[:], "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e501"},
+		{one, tears[:], "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc2100"},
+		{icx, txHash, "ec88558f6f98a9f15ebd4f8b3ff4af9b1ae147a30bdddf74218bf742b70da1a6094230efa06d264703a65675b5a7614b1150d2aa5e19f1906f6ae4e1a14781a500"},
+	}
+	for _, v := range vectors {
+		sig, err := NewSignature(v.hash, v.key)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		rsv, _ := sig.SerializeRSV()
+		assert.Equal(t, v.rsv, hex.EncodeToString(rsv))
+		assert.True(t, sig.IsLowS())
+		assert.True(t, sig.Verify(v.hash, v.key.PublicKey()))
+
+		publicKey, err := sig.RecoverPublicKey(v.hash)
+		assert.Nil(t, err)
+		assert.Equal(t, v.key.PublicKey().String(), publicKey.String())
+	}
+}
+
+func TestNewSignatureMatchesDecred(t *testing.T) {
+	// the constant-time signer produces the signatures of the decred one
+	for i := 0; i < 64; i++ {
+		privateKey, _ := GenerateKey()
+		hash := SHA3Sum256([]byte(fmt.Sprintf("decred-%d", i)))
+		sig, err := NewSignature(hash, privateKey)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(privateKey.bytes), hash, false)
+		expected := append(compact[1:], compact[0]-compactSigMagicOffset)
+		rsv, _ := sig.SerializeRSV()
+		assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(rsv))
+	}
+}
+
+func TestSignatureVerifyRejectsHighS(t *testing.T) {
+	privateKey, publicKey := GenerateKey()
+	hash := SHA3Sum256([]byte("high-s"))
+	sig, err := NewSignature(hash, privateKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	again, _ := NewSignature(hash, privateKey)
+	assert.Equal(t, sig.String(), again.String())
+
+	// (r, N-s) with the flipped recovery id is the malleated signature
+	rsv, _ := sig.SerializeRSV()
+	malleated := make([]byte, SignatureLenRawWithV)
+	copy(malleated, rsv)
+	s := new(big.Int).SetBytes(rsv[32:64])
+	new(big.Int).Sub(secp256k1N, s).FillBytes(malleated[32:64])
+	malleated[64] ^= 1
+	highS, _ := ParseSignature(malleated)
+	assert.False(t, highS.IsLowS())
+	assert.False(t, highS.Verify(hash, publicKey))
+}
+
+func TestNewSignatureConcurrent(t *testing.T) {
+	privateKey, publicKey := GenerateKey()
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			hash := SHA3Sum256([]byte(fmt.Sprintf("concurrent-%d", i)))
+			sig, err := NewSignature(hash, privateKey)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !sig.Verify(hash, publicKey) {
+				errs <- fmt.Errorf("signature %d does not verify", i)
+				return
+			}
+			recovered, err := sig.RecoverPublicKey(hash)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if recovered.String() != publicKey.String() {
+				errs <- fmt.Errorf("signature %d recovers %s", i, recovered)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
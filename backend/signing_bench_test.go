@@ -0,0 +1,140 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// signingConcurrency is the number of goroutines per GOMAXPROCS used by the
+// signing benchmarks.
+var signingConcurrency = []int{1, 4, 16, 64}
+
+// getBenchBackend returns a backend with one account. It logs errors only,
+// as the signing handlers log every request.
+func getBenchBackend(b *testing.B) (logical.Backend, logical.Storage, string) {
+	config := &logical.BackendConfig{
+		Logger:      logging.NewVaultLogger(log.Error),
+		System:      &logical.StaticSystemView{},
+		StorageView: &logical.InmemStorage{},
+		BackendUUID: "bench",
+	}
+	backend, err := Factory(context.Background(), config)
+	if err != nil {
+		b.Fatalf("unable to create backend: %v", err)
+	}
+	req := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "accounts",
+		Storage:   config.StorageView,
+	}
+	resp, err := backend.HandleRequest(context.Background(), req)
+	if err != nil {
+		b.Fatalf("unable to create account: %v", err)
+	}
+	return backend, config.StorageView, resp.Data["address"].(string)
+}
+
+// benchmarkSigning runs newRequest in parallel at each concurrency level and
+// reports the throughput in signatures per second.
+func benchmarkSigning(b *testing.B, newRequest func(storage logical.Storage, address string) *logical.Request) {
+	backend, storage, address := getBenchBackend(b)
+	for _, concurrency := range signingConcurrency {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			b.SetParallelism(concurrency)
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := backend.HandleRequest(context.Background(), newRequest(storage, address)); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "sigs/s")
+		})
+	}
+}
+
+func BenchmarkSignHandler(b *testing.B) {
+	benchmarkSigning(b, func(storage logical.Storage, address string) *logical.Request {
+		return &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "accounts/" + address + "/sign",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"id":      2848,
+				"jsonrpc": "2.0",
+				"method":  "icx_sendTransaction",
+				"params": map[string]interface{}{
+					"from":      address,
+					"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+					"stepLimit": "0x4a817c800",
+					"value":     "0x2386f26fc10000",
+					"timestamp": TimeStampNow(),
+				},
+			},
+		}
+	})
+}
+
+func BenchmarkParamSignHandler(b *testing.B) {
+	benchmarkSigning(b, func(storage logical.Storage, address string) *logical.Request {
+		return &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "accounts/" + address + "/param_sign",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+				"stepLimit": "0x4a817c800",
+				"value":     "0x2386f26fc10000",
+				"nid":       "0x53",
+				"nonce":     "0x1d",
+				"version":   "0x3",
+				"timestamp": TimeStampNow(),
+			},
+		}
+	})
+}
+
+func BenchmarkNewSignature(b *testing.B) {
+	privateKey, _ := GenerateKey()
+	hash := SHA3Sum256([]byte("icx_sendTransaction.from.hx0000000000000000000000000000000000000000"))
+	for _, concurrency := range signingConcurrency {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			b.SetParallelism(concurrency)
+			b.ResetTimer()
+			start := time.Now()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := NewSignature(hash, privateKey); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+			b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "sigs/s")
+		})
+	}
+}
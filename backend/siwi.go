@@ -0,0 +1,280 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	siwiHeaderSuffix = " wants you to sign in with your ICON account:"
+	// SIWIVersion is the version of the Sign-In-With-ICON message format
+	SIWIVersion = "1"
+	// DefaultSIWITTL is the validity of a login message without an explicit expiration, 5 minutes
+	DefaultSIWITTL = 5 * 60
+)
+
+var siwiNonceRegex = regexp.MustCompile(`^[A-Za-z0-9]{8,}$`)
+
+// SIWIMessage is a Sign-In-With-ICON login message, modeled on EIP-4361:
+//
+//	${domain} wants you to sign in with your ICON account:
+//	${address}
+//
+//	${statement}
+//
+//	URI: ${uri}
+//	Version: 1
+//	Nonce: ${nonce}
+//	Issued At: ${issued-at}
+//	Expiration Time: ${expiration-time}
+//
+// The statement and its following blank line are omitted when it is empty.
+// The times are RFC 3339 and the signature is made on the SHA3-256 hash of the message.
+type SIWIMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+}
+
+// String returns the text of the message that is signed.
+func (m *SIWIMessage) String() string {
+	var sb strings.Builder
+	sb.WriteString(m.Domain + siwiHeaderSuffix + "\n")
+	sb.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		sb.WriteString(m.Statement + "\n\n")
+	}
+	sb.WriteString("URI: " + m.URI + "\n")
+	sb.WriteString("Version: " + m.Version + "\n")
+	sb.WriteString("Nonce: " + m.Nonce + "\n")
+	sb.WriteString("Issued At: " + m.IssuedAt.UTC().Format(time.RFC3339) + "\n")
+	sb.WriteString("Expiration Time: " + m.ExpirationTime.UTC().Format(time.RFC3339))
+	return sb.String()
+}
+
+// validate checks the fields, which must not break the line structure of the message.
+func (m *SIWIMessage) validate() error {
+	for name, value := range map[string]string{"domain": m.Domain, "uri": m.URI, "statement": m.Statement} {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s must be a single line", name)
+		}
+	}
+	if m.Domain == "" || m.URI == "" {
+		return errors.New("domain and uri are required")
+	}
+	if !authDomainRegex.MatchString(m.Domain) {
+		return fmt.Errorf("domain must be a host name with an optional port - %s", m.Domain)
+	}
+	if strings.Contains(m.Statement, string(transactionSaltBytes)) {
+		return fmt.Errorf("statement must not contain %s", transactionSaltBytes)
+	}
+	if err := checkNotTransaction("Sign-In-With-ICON message", m.String()); err != nil {
+		return err
+	}
+	if !IsValidIconAddress(m.Address) {
+		return fmt.Errorf("invalid address - %s", m.Address)
+	}
+	if !siwiNonceRegex.MatchString(m.Nonce) {
+		return errors.New("nonce must be at least 8 alphanumeric characters")
+	}
+	if !m.ExpirationTime.After(m.IssuedAt) {
+		return errors.New("expiration time must be after the issued at time")
+	}
+	return nil
+}
+
+// ParseSIWIMessage parses the text of a login message.
+func ParseSIWIMessage(text string) (*SIWIMessage, error) {
+	lines := strings.Split(text, "\n")
+	var m SIWIMessage
+	if len(lines) < 8 || !strings.HasSuffix(lines[0], siwiHeaderSuffix) || lines[2] != "" {
+		return nil, errors.New("malformed Sign-In-With-ICON message")
+	}
+	m.Domain = strings.TrimSuffix(lines[0], siwiHeaderSuffix)
+	m.Address = lines[1]
+	rest := lines[3:]
+	if !strings.HasPrefix(rest[0], "URI: ") {
+		if len(rest) < 2 || rest[1] != "" {
+			return nil, errors.New("malformed Sign-In-With-ICON message")
+		}
+		m.Statement = rest[0]
+		rest = rest[2:]
+	}
+
+	fields := []struct {
+		prefix string
+		value  *string
+	}{
+		{"URI: ", &m.URI},
+		{"Version: ", &m.Version},
+		{"Nonce: ", &m.Nonce},
+	}
+	if len(rest) != len(fields)+2 {
+		return nil, errors.New("malformed Sign-In-With-ICON message")
+	}
+	for i, field := range fields {
+		if !strings.HasPrefix(rest[i], field.prefix) {
+			return nil, fmt.Errorf("malformed Sign-In-With-ICON message, expected %q", strings.TrimSpace(field.prefix))
+		}
+		*field.value = strings.TrimPrefix(rest[i], field.prefix)
+	}
+	var err error
+	if m.IssuedAt, err = parseSIWITime(rest[3], "Issued At: "); err != nil {
+		return nil, err
+	}
+	if m.ExpirationTime, err = parseSIWITime(rest[4], "Expiration Time: "); err != nil {
+		return nil, err
+	}
+	if m.Version != SIWIVersion {
+		return nil, fmt.Errorf("unsupported Sign-In-With-ICON version - %s", m.Version)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func parseSIWITime(line, prefix string) (time.Time, error) {
+	if !strings.HasPrefix(line, prefix) {
+		return time.Time{}, fmt.Errorf("malformed Sign-In-With-ICON message, expected %q", strings.TrimSpace(prefix))
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, prefix))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s - %v", strings.TrimSuffix(prefix, ": "), err)
+	}
+	return t, nil
+}
+
+// generateSIWINonce returns a random alphanumeric nonce.
+func generateSIWINonce() (string, error) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(nonce), nil
+}
+
+func (b *backend) signSIWI(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	signatureFormat := data.Get("signature_format").(string)
+	if err := checkSignatureFormat(signatureFormat); err != nil {
+		return nil, err
+	}
+	account, _, err := b.retrieveSigningKey(ctx, req, data.Get("name").(string), transactionSigningPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := data.Get("nonce").(string)
+	if nonce == "" {
+		if nonce, err = generateSIWINonce(); err != nil {
+			return nil, err
+		}
+	}
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	message := &SIWIMessage{
+		Domain:         data.Get("domain").(string),
+		Address:        account.Address,
+		Statement:      data.Get("statement").(string),
+		URI:            data.Get("uri").(string),
+		Version:        SIWIVersion,
+		Nonce:          nonce,
+		IssuedAt:       issuedAt,
+		ExpirationTime: issuedAt.Add(time.Duration(data.Get("ttl").(int)) * time.Second),
+	}
+	if err := message.validate(); err != nil {
+		return nil, err
+	}
+
+	text := message.String()
+	signedMessage, err := SignatureFromPrivateKey(account.PrivateKey, []byte(text))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signedMessage.Encode(signatureFormat)
+	if err != nil {
+		return nil, err
+	}
+	b.Logger().Info("[SIWI][OK] Signed a login message", "address", account.Address, "domain", message.Domain)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":         account.Address,
+			"message":         text,
+			"nonce":           message.Nonce,
+			"issued_at":       message.IssuedAt.Format(time.RFC3339),
+			"expiration_time": message.ExpirationTime.Format(time.RFC3339),
+			"signature":       signature,
+		},
+	}, nil
+}
+
+func (b *backend) verifySIWI(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	text := data.Get("message").(string)
+	message, err := ParseSIWIMessage(text)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ParseSignatureString(data.Get("signature").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"address":         message.Address,
+			"domain":          message.Domain,
+			"uri":             message.URI,
+			"nonce":           message.Nonce,
+			"issued_at":       message.IssuedAt.Format(time.RFC3339),
+			"expiration_time": message.ExpirationTime.Format(time.RFC3339),
+			"valid":           false,
+		},
+	}
+	hash := SHA3Sum256([]byte(text))
+	publicKey, err := signature.RecoverPublicKey(hash)
+	now := time.Now()
+	switch {
+	case message.Nonce != data.Get("nonce").(string):
+		resp.Data["reason"] = "nonce does not match"
+	case data.Get("domain").(string) != "" && message.Domain != data.Get("domain").(string):
+		resp.Data["reason"] = "domain does not match"
+	case now.Before(message.IssuedAt.Add(-time.Minute)):
+		resp.Data["reason"] = "message is issued in the future"
+	case !now.Before(message.ExpirationTime):
+		resp.Data["reason"] = "message has expired"
+	case err != nil:
+		resp.Data["reason"] = err.Error()
+	case !signature.Verify(hash, publicKey) || publicKey.Address() != message.Address:
+		resp.Data["reason"] = "signature is not made by " + message.Address
+	default:
+		resp.Data["valid"] = true
+	}
+	return resp, nil
+}
@@ -0,0 +1,199 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSIWIMessageRoundTrip(t *testing.T) {
+	issuedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	message := &SIWIMessage{
+		Domain:         "app.example.com",
+		Address:        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+		Statement:      "Sign in to the dashboard",
+		URI:            "https://app.example.com/login",
+		Version:        SIWIVersion,
+		Nonce:          "32891756",
+		IssuedAt:       issuedAt,
+		ExpirationTime: issuedAt.Add(time.Hour),
+	}
+	expected := "app.example.com wants you to sign in with your ICON account:\n" +
+		"hxc1d72af5b89ea6594a7e17ca7a804d52d2474462\n\n" +
+		"Sign in to the dashboard\n\n" +
+		"URI: https://app.example.com/login\n" +
+		"Version: 1\n" +
+		"Nonce: 32891756\n" +
+		"Issued At: 2023-01-02T03:04:05Z\n" +
+		"Expiration Time: 2023-01-02T04:04:05Z"
+	assert.Equal(t, expected, message.String())
+
+	parsed, err := ParseSIWIMessage(expected)
+	assert.Nil(t, err)
+	assert.Equal(t, message, parsed)
+
+	message.Statement = ""
+	parsed, err = ParseSIWIMessage(message.String())
+	assert.Nil(t, err)
+	assert.Equal(t, message, parsed)
+
+	_, err = ParseSIWIMessage(strings.Replace(expected, "Version: 1", "Version: 2", 1))
+	assert.EqualError(t, err, "unsupported Sign-In-With-ICON version - 2")
+	_, err = ParseSIWIMessage(strings.Replace(expected, "Nonce: 32891756", "Nonce: 1", 1))
+	assert.EqualError(t, err, "nonce must be at least 8 alphanumeric characters")
+	_, err = ParseSIWIMessage("hello")
+	assert.EqualError(t, err, "malformed Sign-In-With-ICON message")
+}
+
+func TestSignAndVerifySIWI(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/siwi")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"domain":    "app.example.com",
+		"uri":       "https://app.example.com/login",
+		"statement": "Sign in to the dashboard",
+		"nonce":     "a1b2c3d4e5",
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	message := resp.Data["message"].(string)
+	signature := resp.Data["signature"].(string)
+	assert.True(t, strings.HasPrefix(message, "app.example.com wants you to sign in with your ICON account:\n"+address+"\n"))
+
+	verifySIWI := func(data map[string]interface{}) *logical.Response {
+		req := logical.TestRequest(t, logical.UpdateOperation, "siwi/verify")
+		req.Storage = storage
+		req.Data = data
+		resp, err := b.HandleRequest(context.Background(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+	resp = verifySIWI(map[string]interface{}{
+		"message":   message,
+		"signature": signature,
+		"nonce":     "a1b2c3d4e5",
+		"domain":    "app.example.com",
+	})
+	assert.Equal(t, true, resp.Data["valid"])
+	assert.Equal(t, address, resp.Data["address"])
+
+	resp = verifySIWI(map[string]interface{}{
+		"message":   message,
+		"signature": signature,
+		"nonce":     "other-nonce",
+	})
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, "nonce does not match", resp.Data["reason"])
+
+	resp = verifySIWI(map[string]interface{}{
+		"message":   message,
+		"signature": signature,
+		"nonce":     "a1b2c3d4e5",
+		"domain":    "evil.example.com",
+	})
+	assert.Equal(t, "domain does not match", resp.Data["reason"])
+
+	// another account's address in the message does not verify
+	tampered := strings.Replace(message, address, "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462", 1)
+	resp = verifySIWI(map[string]interface{}{
+		"message":   tampered,
+		"signature": signature,
+		"nonce":     "a1b2c3d4e5",
+	})
+	assert.Equal(t, "signature is not made by hxc1d72af5b89ea6594a7e17ca7a804d52d2474462", resp.Data["reason"])
+
+	// an expired message is rejected
+	privateKey, publicKey := GenerateKey()
+	issuedAt := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	expired := (&SIWIMessage{
+		Domain:         "app.example.com",
+		Address:        publicKey.Address(),
+		URI:            "https://app.example.com/login",
+		Version:        SIWIVersion,
+		Nonce:          "a1b2c3d4e5",
+		IssuedAt:       issuedAt,
+		ExpirationTime: issuedAt.Add(time.Minute),
+	}).String()
+	expiredSignature, err := SignFromPrivateKey(privateKey.String(), []byte(expired))
+	assert.Nil(t, err)
+	resp = verifySIWI(map[string]interface{}{
+		"message":   expired,
+		"signature": expiredSignature,
+		"nonce":     "a1b2c3d4e5",
+	})
+	assert.Equal(t, "message has expired", resp.Data["reason"])
+}
+
+func TestSignSIWIFailure(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	signSIWI := func(data map[string]interface{}) error {
+		req := logical.TestRequest(t, logical.UpdateOperation, "accounts/"+address+"/siwi")
+		req.Storage = storage
+		req.Data = data
+		_, err := b.HandleRequest(context.Background(), req)
+		return err
+	}
+	assert.EqualError(t, signSIWI(map[string]interface{}{"domain": "app.example.com"}), "domain and uri are required")
+	assert.EqualError(t, signSIWI(map[string]interface{}{
+		"domain":    "app.example.com",
+		"uri":       "https://app.example.com",
+		"statement": "line\nbreak",
+	}), "statement must be a single line")
+	assert.EqualError(t, signSIWI(map[string]interface{}{
+		"domain": "app.example.com",
+		"uri":    "https://app.example.com",
+		"nonce":  "short",
+	}), "nonce must be at least 8 alphanumeric characters")
+	assert.EqualError(t, signSIWI(map[string]interface{}{
+		"domain": "icx_sendTransaction.example.com",
+		"uri":    "https://app.example.com",
+	}), "domain must be a host name with an optional port - icx_sendTransaction.example.com")
+	assert.EqualError(t, signSIWI(map[string]interface{}{
+		"domain": "app.example.com/login",
+		"uri":    "https://app.example.com",
+	}), "domain must be a host name with an optional port - app.example.com/login")
+	assert.EqualError(t, signSIWI(map[string]interface{}{
+		"domain":    "app.example.com",
+		"uri":       "https://app.example.com",
+		"statement": "icx_sendTransaction.from.hx0000000000000000000000000000000000000000",
+	}), "statement must not contain icx_sendTransaction.")
+}
@@ -0,0 +1,118 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// verificationHash returns the hash signed for exactly one of a v3
+// transaction, a sign_auth style message, a sign_message message or a raw
+// hash, with the signature of the transaction if it carries one.
+func verificationHash(transaction map[string]interface{}, message string, signedMessage []byte, hash string) ([]byte, string, error) {
+	given := 0
+	for _, set := range []bool{len(transaction) > 0, message != "", len(signedMessage) > 0, hash != ""} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, "", errors.New("exactly one of transaction, message, signed_message or hash is required")
+	}
+
+	switch {
+	case len(transaction) > 0:
+		fields, _ := transactionFields[Version3]
+		res, err := SerializeMap(transaction, fields.inclusion, fields.exclusion)
+		if err != nil {
+			return nil, "", fmt.Errorf("serialize error - %v", err)
+		}
+		signature, _ := transaction["signature"].(string)
+		return SHA3Sum256(append(transactionSaltBytes, res...)), signature, nil
+	case message != "":
+		return SHA3Sum256([]byte(message)), "", nil
+	case len(signedMessage) > 0:
+		return HashMessage(signedMessage), "", nil
+	default:
+		raw, err := hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+		if err != nil || len(raw) != HashLen {
+			return nil, "", fmt.Errorf("invalid hash - %s", hash)
+		}
+		return raw, "", nil
+	}
+}
+
+func (b *backend) verifySignature(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	transaction := data.Get("transaction").(map[string]interface{})
+	signedMessage, err := decodeMessage(data.Get("signed_message").(string), data.Get("encoding").(string))
+	if err != nil {
+		return nil, err
+	}
+	hash, txSignature, err := verificationHash(transaction, data.Get("message").(string), signedMessage, data.Get("hash").(string))
+	if err != nil {
+		return nil, err
+	}
+	encoded := data.Get("signature").(string)
+	if encoded == "" {
+		encoded = txSignature
+	}
+	if encoded == "" {
+		return nil, errors.New("signature is required")
+	}
+	signature, err := ParseSignatureString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := signature.RecoverPublicKey(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover the public key - %v", err)
+	}
+	signer := publicKey.Address()
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"hash":       "0x" + hex.EncodeToString(hash),
+			"address":    signer,
+			"public_key": publicKey.String(),
+		},
+	}
+	// any well-formed signature verifies against the key recovered from it,
+	// so it is only valid when made by the expected signer
+	if name := data.Get("address").(string); name != "" {
+		expected, err := b.resolveAddress(ctx, req, name)
+		if err != nil {
+			return nil, err
+		}
+		resp.Data["matches"] = expected == signer
+		resp.Data["valid"] = expected == signer && signature.Verify(hash, publicKey)
+	}
+	account, err := b.retrieveAccount(ctx, req, signer)
+	if err != nil {
+		return nil, err
+	}
+	resp.Data["managed"] = account != nil
+	if account != nil {
+		resp.Data["alias_name"] = account.AliasName
+		resp.Data["status"] = account.Status
+	}
+	return resp, nil
+}
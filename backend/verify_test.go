@@ -0,0 +1,165 @@
+// Copyright © 2022 Jinwoo
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/assert"
+)
+
+func verify(t *testing.T, b logical.Backend, storage logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+	req := logical.TestRequest(t, logical.UpdateOperation, "verify")
+	req.Storage = storage
+	req.Data = data
+	return b.HandleRequest(context.Background(), req)
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	b, storage := getBackend(t)
+	req := logical.TestRequest(t, logical.UpdateOperation, "accounts")
+	req.Storage = storage
+	req.Data = map[string]interface{}{"name": "verifier"}
+	res, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	address := res.Data["address"].(string)
+
+	req = logical.TestRequest(t, logical.CreateOperation, "accounts/"+address+"/param_sign")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"to":        "hxc1d72af5b89ea6594a7e17ca7a804d52d2474462",
+		"stepLimit": "0x4a817c800",
+		"value":     "0x2386f26fc10000",
+		"nid":       "0x53",
+		"nonce":     "0x1d",
+		"version":   "0x3",
+		"timestamp": "0x5d3d0b7b3c1e0",
+	}
+	signed, err := b.HandleRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	transaction := signed.Data["signed_params"].(map[string]interface{})
+
+	resp, err := verify(t, b, storage, map[string]interface{}{
+		"transaction": transaction,
+		"address":     "verifier",
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, address, resp.Data["address"])
+	assert.Equal(t, true, resp.Data["valid"])
+	assert.Equal(t, true, resp.Data["matches"])
+	assert.Equal(t, true, resp.Data["managed"])
+	assert.Equal(t, "verifier", resp.Data["alias_name"])
+
+	// a tampered transaction recovers another signer
+	transaction["value"] = "0x1"
+	resp, err = verify(t, b, storage, map[string]interface{}{
+		"transaction": transaction,
+		"address":     address,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.NotEqual(t, address, resp.Data["address"])
+	assert.Equal(t, false, resp.Data["matches"])
+	assert.Equal(t, false, resp.Data["valid"])
+	assert.Equal(t, false, resp.Data["managed"])
+}
+
+func TestVerifyMessageAndHash(t *testing.T) {
+	b, storage := getBackend(t)
+	privateKey, publicKey := GenerateKey()
+	message := publicKey.Address() + "1660000000"
+	hash := SHA3Sum256([]byte(message))
+	sig, _ := NewSignature(hash, privateKey)
+	b64Sig, _ := sig.EncodeBase64()
+
+	resp, err := verify(t, b, storage, map[string]interface{}{
+		"message":   message,
+		"signature": b64Sig,
+		"address":   publicKey.Address(),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, publicKey.Address(), resp.Data["address"])
+	assert.Equal(t, publicKey.String(), resp.Data["public_key"])
+	assert.Equal(t, true, resp.Data["matches"])
+	assert.Equal(t, true, resp.Data["valid"])
+	assert.Equal(t, false, resp.Data["managed"])
+
+	rsv, _ := sig.SerializeRSV()
+	resp, err = verify(t, b, storage, map[string]interface{}{
+		"hash":      "0x" + hex.EncodeToString(hash),
+		"signature": "0x" + hex.EncodeToString(rsv),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, publicKey.Address(), resp.Data["address"])
+	assert.Nil(t, resp.Data["valid"])
+	assert.Nil(t, resp.Data["matches"])
+
+	// the malleated high-S signature recovers the same signer but is not valid
+	malleated := make([]byte, SignatureLenRawWithV)
+	copy(malleated, rsv)
+	new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(rsv[32:64])).FillBytes(malleated[32:64])
+	malleated[64] ^= 1
+	resp, err = verify(t, b, storage, map[string]interface{}{
+		"hash":      "0x" + hex.EncodeToString(hash),
+		"signature": "0x" + hex.EncodeToString(malleated),
+		"address":   publicKey.Address(),
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, true, resp.Data["matches"])
+	assert.Equal(t, false, resp.Data["valid"])
+}
+
+func TestVerifyFailure(t *testing.T) {
+	b, storage := getBackend(t)
+	_, err := verify(t, b, storage, map[string]interface{}{
+		"message": "hello",
+		"hash":    "0x00",
+	})
+	assert.EqualError(t, err, "exactly one of transaction, message, signed_message or hash is required")
+
+	_, err = verify(t, b, storage, map[string]interface{}{
+		"message": "hello",
+	})
+	assert.EqualError(t, err, "signature is required")
+
+	_, err = verify(t, b, storage, map[string]interface{}{
+		"hash":      "0x1234",
+		"signature": "0x00",
+	})
+	assert.EqualError(t, err, "invalid hash - 0x1234")
+
+	_, err = verify(t, b, storage, map[string]interface{}{
+		"message":   "hello",
+		"signature": "0x00",
+	})
+	assert.EqualError(t, err, "invalid signature length 1, expected 65")
+}
@@ -4,12 +4,15 @@ go 1.18
 
 require (
 	github.com/davecgh/go-spew v1.1.1
-	github.com/haltingstate/secp256k1-go v0.0.0-20151224084235-572209b26df6
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.2.0
 	github.com/hashicorp/go-hclog v0.8.0
+	github.com/hashicorp/go-uuid v1.0.1
 	github.com/hashicorp/vault/api v1.0.4
 	github.com/hashicorp/vault/sdk v0.1.13
 	github.com/k0kubun/pp/v3 v3.1.0
+	github.com/kilic/bls12-381 v0.1.0
 	github.com/stretchr/testify v1.7.0
+	github.com/tyler-smith/go-bip39 v1.1.0
 	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 )
 
@@ -25,7 +28,6 @@ require (
 	github.com/hashicorp/go-retryablehttp v0.5.4 // indirect
 	github.com/hashicorp/go-rootcerts v1.0.1 // indirect
 	github.com/hashicorp/go-sockaddr v1.0.2 // indirect
-	github.com/hashicorp/go-uuid v1.0.1 // indirect
 	github.com/hashicorp/go-version v1.1.0 // indirect
 	github.com/hashicorp/golang-lru v0.5.5-0.20210104140557-80c98217689d // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect